@@ -12,9 +12,25 @@ import (
 )
 
 func main() {
+	mountCwd, err := shorthandSchema[config.MountCwdSpec]("boolean")
+	if err != nil {
+		log.Fatal(err)
+	}
+	mount, err := shorthandSchema[config.MountSpec]("string")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cache, err := shorthandSchema[config.CacheSpec]("string")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	opts := &jsonschema.ForOptions{
 		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
-			reflect.TypeFor[config.DeviceCount](): deviceCountSchema(),
+			reflect.TypeFor[config.DeviceCount]():  deviceCountSchema(),
+			reflect.TypeFor[config.MountCwdSpec](): mountCwd,
+			reflect.TypeFor[config.MountSpec]():    mount,
+			reflect.TypeFor[config.CacheSpec]():    cache,
 		},
 	}
 
@@ -40,3 +56,15 @@ func deviceCountSchema() *jsonschema.Schema {
 		},
 	}
 }
+
+// shorthandSchema returns the object schema of T that also accepts a scalar shorthand;
+// properties only constrain the object form.
+func shorthandSchema[T any](shorthand string) (*jsonschema.Schema, error) {
+	s, err := jsonschema.For[T](nil)
+	if err != nil {
+		return nil, err
+	}
+	s.Type = ""
+	s.Types = []string{shorthand, "object"}
+	return s, nil
+}
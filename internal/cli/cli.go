@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -15,11 +16,15 @@ func Execute(version string) {
 	}
 }
 
+// logEnv sets the default log level; --log-level, -v and -q override it.
+const logEnv = "CRADLE_LOG"
+
 func ExecuteArgs(version string, args []string) error {
-	log := logging.New(os.Stdout)
-	errLog := logging.New(os.Stderr)
+	logOpts := &logging.Options{Format: logging.FormatText}
+	log := logging.NewWithOptions(os.Stdout, logOpts)
+	errLog := logging.NewWithOptions(os.Stderr, logOpts)
 
-	root := NewRootCmd(version, log)
+	root := NewRootCmd(version, log, logOpts)
 	root.SetArgs(args)
 	if err := root.Execute(); err != nil {
 		errLog.Error("command failed", "error", err)
@@ -29,15 +34,32 @@ func ExecuteArgs(version string, args []string) error {
 	return nil
 }
 
-func NewRootCmd(version string, log *slog.Logger) *cobra.Command {
+// NewRootCmd returns the cradle command. Its global logging flags are applied to
+// logOpts before any subcommand runs.
+func NewRootCmd(version string, log *slog.Logger, logOpts *logging.Options) *cobra.Command {
 	var cfgPath string
 	var showVersion bool
+	var logLevel, logFormat string
+	var verbose, quiet bool
 
 	root := &cobra.Command{
 		Use:           "cradle",
 		Short:         "Build and launch preconfigured Docker dev shells",
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			level, err := ResolveLogLevel(logLevel, verbose, quiet)
+			if err != nil {
+				return err
+			}
+			format, err := logging.ParseFormat(logFormat)
+			if err != nil {
+				return err
+			}
+			logOpts.Level.Set(level)
+			logOpts.Format = format
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if showVersion {
 				log.Info("version", "version", version)
@@ -52,14 +74,56 @@ func NewRootCmd(version string, log *slog.Logger) *cobra.Command {
 
 	root.PersistentFlags().
 		StringVarP(&cfgPath, "config", "c", "", "config file (default is $XDG_CONFIG_HOME/cradle/config.yaml)")
+	root.PersistentFlags().
+		StringVar(&logLevel, "log-level", "", "log level: debug|info|warn|error (default $"+logEnv+" or info)")
+	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log debug details (same as --log-level debug)")
+	root.PersistentFlags().
+		BoolVarP(&quiet, "quiet", "q", false, "log only warnings and errors (same as --log-level warn)")
+	root.PersistentFlags().StringVar(&logFormat, "log-format", string(logging.FormatText), "log format: text|json")
+	root.MarkFlagsMutuallyExclusive("log-level", "verbose", "quiet")
 	root.Flags().BoolVarP(&showVersion, "version", "V", false, "print version")
 
 	root.AddCommand(
 		NewBuildCmd(&cfgPath, log),
+		NewCommitCmd(&cfgPath, log),
+		NewConfigCmd(&cfgPath, log),
+		NewDoctorCmd(&cfgPath, log),
+		NewDownCmd(&cfgPath, log),
+		NewExecCmd(&cfgPath, log),
+		NewLoadCmd(&cfgPath, log),
+		NewLockCmd(&cfgPath, log),
+		NewLogsCmd(&cfgPath, log),
 		NewLsCmd(&cfgPath, log),
+		NewOpenCmd(&cfgPath, log),
+		NewOutdatedCmd(&cfgPath, log),
+		NewPortCmd(&cfgPath, log),
+		NewRmCmd(&cfgPath, log),
 		NewRunCmd(&cfgPath, log),
+		NewSaveCmd(&cfgPath, log),
 		NewStopCmd(&cfgPath, log),
+		NewUpCmd(&cfgPath, log),
 	)
 
 	return root
 }
+
+// ResolveLogLevel picks the log level from --log-level, then -v or -q, then the
+// CRADLE_LOG environment variable, and defaults to info.
+func ResolveLogLevel(flag string, verbose, quiet bool) (slog.Level, error) {
+	switch {
+	case flag != "":
+		return logging.ParseLevel(flag)
+	case verbose:
+		return slog.LevelDebug, nil
+	case quiet:
+		return slog.LevelWarn, nil
+	}
+	if env := os.Getenv(logEnv); env != "" {
+		level, err := logging.ParseLevel(env)
+		if err != nil {
+			return slog.LevelInfo, fmt.Errorf("%s: %w", logEnv, err)
+		}
+		return level, nil
+	}
+	return slog.LevelInfo, nil
+}
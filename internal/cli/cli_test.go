@@ -6,11 +6,14 @@ import (
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/cli"
+	"github.com/rhajizada/cradle/internal/logging"
+
+	"github.com/spf13/cobra"
 )
 
 func TestRootCommandWiring(t *testing.T) {
 	log := slog.New(slog.DiscardHandler)
-	root := cli.NewRootCmd("test", log)
+	root := cli.NewRootCmd("test", log, &logging.Options{})
 
 	if root.Use != "cradle" {
 		t.Fatalf("unexpected root Use: %q", root.Use)
@@ -21,7 +24,7 @@ func TestRootCommandWiring(t *testing.T) {
 		sub[c.Name()] = true
 	}
 
-	for _, name := range []string{"build", "ls", "run", "stop"} {
+	for _, name := range []string{"build", "config", "doctor", "exec", "logs", "ls", "rm", "run", "stop"} {
 		if !sub[name] {
 			t.Fatalf("missing subcommand %q", name)
 		}
@@ -44,11 +47,20 @@ func TestCommandBuilders(t *testing.T) {
 	if got := cli.NewStopCmd(&cfg, log).Use; got == "" {
 		t.Fatalf("stop command Use is empty")
 	}
+	if got := cli.NewRmCmd(&cfg, log).Use; got == "" {
+		t.Fatalf("rm command Use is empty")
+	}
+	if got := cli.NewLogsCmd(&cfg, log).Use; got == "" {
+		t.Fatalf("logs command Use is empty")
+	}
+	if got := cli.NewExecCmd(&cfg, log).Use; got == "" {
+		t.Fatalf("exec command Use is empty")
+	}
 }
 
 func TestRootCommandVersionAndHelp(t *testing.T) {
 	log := slog.New(slog.DiscardHandler)
-	root := cli.NewRootCmd("test", log)
+	root := cli.NewRootCmd("test", log, &logging.Options{})
 	root.SetOut(io.Discard)
 	root.SetErr(io.Discard)
 
@@ -57,7 +69,7 @@ func TestRootCommandVersionAndHelp(t *testing.T) {
 		t.Fatalf("version execute error: %v", err)
 	}
 
-	root = cli.NewRootCmd("test", log)
+	root = cli.NewRootCmd("test", log, &logging.Options{})
 	root.SetOut(io.Discard)
 	root.SetErr(io.Discard)
 	root.SetArgs([]string{})
@@ -65,3 +77,52 @@ func TestRootCommandVersionAndHelp(t *testing.T) {
 		t.Fatalf("help execute error: %v", err)
 	}
 }
+
+func TestResolveLogLevel(t *testing.T) {
+	t.Setenv("CRADLE_LOG", "error")
+	tests := []struct {
+		flag           string
+		verbose, quiet bool
+		want           slog.Level
+	}{
+		{want: slog.LevelError},
+		{flag: "debug", want: slog.LevelDebug},
+		{verbose: true, want: slog.LevelDebug},
+		{quiet: true, want: slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		got, err := cli.ResolveLogLevel(tt.flag, tt.verbose, tt.quiet)
+		if err != nil || got != tt.want {
+			t.Fatalf("ResolveLogLevel(%q, %v, %v) = %v, %v", tt.flag, tt.verbose, tt.quiet, got, err)
+		}
+	}
+
+	t.Setenv("CRADLE_LOG", "loud")
+	if _, err := cli.ResolveLogLevel("", false, false); err == nil {
+		t.Fatalf("expected invalid CRADLE_LOG to fail")
+	}
+}
+
+func TestRootCommandAppliesLogFlags(t *testing.T) {
+	t.Setenv("CRADLE_LOG", "")
+	opts := &logging.Options{}
+	root := cli.NewRootCmd("test", slog.New(slog.DiscardHandler), opts)
+	root.AddCommand(&cobra.Command{Use: "noop", RunE: func(*cobra.Command, []string) error { return nil }})
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"noop", "-v", "--log-format", "json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if opts.Level.Level() != slog.LevelDebug || opts.Format != logging.FormatJSON {
+		t.Fatalf("unexpected log options: %v %q", opts.Level.Level(), opts.Format)
+	}
+
+	root = cli.NewRootCmd("test", slog.New(slog.DiscardHandler), &logging.Options{})
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"-v", "-q", "ls"})
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected -v and -q together to fail")
+	}
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -33,6 +35,7 @@ func NewApp(cfgPath string, log *slog.Logger) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	svc.SetLogger(log)
 	return &App{
 		Cfg:      cfg,
 		Svc:      svc,
@@ -40,9 +43,24 @@ func NewApp(cfgPath string, log *slog.Logger) (*App, error) {
 	}, nil
 }
 
+// policyOverrides maps the --build and --pull flags to image policy overrides.
+func policyOverrides(forceBuild, forcePull bool) service.ImagePolicyOverrides {
+	overrides := service.ImagePolicyOverrides{}
+	if forceBuild {
+		policy := config.ImagePolicyAlways
+		overrides.Build = &policy
+	}
+	if forcePull {
+		policy := config.ImagePolicyAlways
+		overrides.Pull = &policy
+	}
+	return overrides
+}
+
 func NewBuildCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 	var forceBuild bool
 	var forcePull bool
+	var logFile string
 
 	cmd := &cobra.Command{
 		Use:   "build <alias|all>",
@@ -59,15 +77,8 @@ func NewBuildCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 				}
 			}()
 
-			overrides := service.ImagePolicyOverrides{}
-			if forceBuild {
-				policy := config.ImagePolicyAlways
-				overrides.Build = &policy
-			}
-			if forcePull {
-				policy := config.ImagePolicyAlways
-				overrides.Pull = &policy
-			}
+			overrides := policyOverrides(forceBuild, forcePull)
+			overrides.LogFile = logFile
 
 			target := args[0]
 			if target == "all" {
@@ -91,6 +102,7 @@ func NewBuildCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 
 	cmd.Flags().BoolVar(&forceBuild, "build", false, "force build images")
 	cmd.Flags().BoolVar(&forcePull, "pull", false, "force pull images")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "append pull and build output to this file instead of the state log")
 	return cmd
 }
 
@@ -122,9 +134,12 @@ func NewLsCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 func NewRunCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 	var forceBuild bool
 	var forcePull bool
+	var here bool
+	var ephemeral bool
+	var jsonOut bool
 
 	cmd := &cobra.Command{
-		Use:   "run <alias>",
+		Use:   "run <alias[@instance]>",
 		Short: "Run alias interactively",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
@@ -138,29 +153,40 @@ func NewRunCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 				}
 			}()
 
-			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 			defer stop()
 
-			info, err := app.Svc.AliasInfo(args[0])
+			target, err := service.ParseTarget(args[0])
 			if err != nil {
 				return err
 			}
-			app.Renderer.BuildStart(info)
-			overrides := service.ImagePolicyOverrides{}
-			if forceBuild {
-				policy := config.ImagePolicyAlways
-				overrides.Build = &policy
-			}
-			if forcePull {
-				policy := config.ImagePolicyAlways
-				overrides.Pull = &policy
+			info, err := app.Svc.AliasInfo(target.Alias)
+			if err != nil {
+				return err
 			}
+			app.Renderer.BuildStart(info)
+			overrides := policyOverrides(forceBuild, forcePull)
 
-			result, err := app.Svc.Run(ctx, args[0], os.Stdout, overrides)
+			// With --json, stdout carries only the result; progress and hook output go to stderr.
+			progress := io.Writer(os.Stdout)
+			if jsonOut {
+				progress = os.Stderr
+			}
+			result, err := app.Svc.Run(ctx, target, progress, overrides, service.RunOptions{
+				Here:      here,
+				Ephemeral: ephemeral,
+			})
 			if err != nil {
 				return err
 			}
-			app.Renderer.RunStart(result.ID)
+			if jsonOut {
+				if jsonErr := app.Renderer.RunJSON(result); jsonErr != nil {
+					return jsonErr
+				}
+			} else {
+				app.Renderer.RunStart(result.ID)
+				app.Renderer.RunPorts(result.Ports)
+			}
 			if !result.Attach {
 				return nil
 			}
@@ -178,12 +204,111 @@ func NewRunCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 
 	cmd.Flags().BoolVar(&forceBuild, "build", false, "force build images")
 	cmd.Flags().BoolVar(&forcePull, "pull", false, "force pull images")
+	cmd.Flags().BoolVar(&here, "here", false, "mount the current project directory")
+	cmd.Flags().BoolVar(&ephemeral, "rm", false, "run a throwaway container that is removed on exit")
+	cmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "alias for --rm")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the started container and its published ports as JSON")
 	return cmd
 }
 
-func NewStopCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+func NewUpCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var forceBuild bool
+	var forcePull bool
+
+	cmd := &cobra.Command{
+		Use:   "up <group|alias>",
+		Short: "Start a group or alias and its dependencies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+			defer stop()
+
+			order, err := app.Svc.StartOrder(args[0])
+			if err != nil {
+				return err
+			}
+			// Only an alias named on the command line is attached; groups start detached.
+			_, namedAlias := app.Cfg.Aliases[args[0]]
+			overrides := policyOverrides(forceBuild, forcePull)
+			var attach *service.RunResult
+			for _, alias := range order {
+				info, infoErr := app.Svc.AliasInfo(alias)
+				if infoErr != nil {
+					return infoErr
+				}
+				app.Renderer.BuildStart(info)
+				result, runErr := app.Svc.Run(ctx, service.Target{Alias: alias}, os.Stdout, overrides, service.RunOptions{})
+				if runErr != nil {
+					return fmt.Errorf("up %s: %w", alias, runErr)
+				}
+				app.Renderer.RunStart(result.ID)
+				app.Renderer.RunPorts(result.Ports)
+				if namedAlias && alias == args[0] {
+					attach = result
+					continue
+				}
+				if waitErr := app.Svc.WaitHealthy(ctx, result.ID); waitErr != nil {
+					return fmt.Errorf("up %s: %w", alias, waitErr)
+				}
+			}
+			if attach == nil || !attach.Attach {
+				return nil
+			}
+			return app.Svc.AttachAndWait(ctx, service.AttachOptions{
+				ID:         attach.ID,
+				AutoRemove: attach.AutoRemove,
+				TTY:        attach.TTY,
+				Stdin:      os.Stdin,
+				Stdout:     os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&forceBuild, "build", false, "force build images")
+	cmd.Flags().BoolVar(&forcePull, "pull", false, "force pull images")
+	return cmd
+}
+
+func NewDownCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 	return &cobra.Command{
-		Use:   "stop <alias>",
+		Use:   "down <group|alias>",
+		Short: "Stop a group or alias and its dependencies in reverse order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			stopped, err := app.Svc.Down(context.Background(), args[0])
+			for _, id := range stopped {
+				app.Renderer.RunStop(id)
+			}
+			return err
+		},
+	}
+}
+
+func NewStopCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+
+	cmd := &cobra.Command{
+		Use:   "stop <alias[@instance]>",
 		Short: "Stop alias container",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive // cmd needed for cobra signature
@@ -200,7 +325,11 @@ func NewStopCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 
-			id, err := app.Svc.Stop(ctx, args[0])
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			id, err := app.Svc.Stop(ctx, target, here)
 			if err != nil {
 				return err
 			}
@@ -208,4 +337,517 @@ func NewStopCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "stop the container for the current project directory")
+	return cmd
+}
+
+func NewRmCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "rm <alias[@instance]>",
+		Short: "Remove alias container",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			id, err := app.Svc.Remove(ctx, target, here, force)
+			if err != nil {
+				return err
+			}
+			app.Renderer.RunRemove(id)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "remove the container for the current project directory")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "remove a running container")
+	return cmd
+}
+
+func NewLogsCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+	opts := service.LogsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "logs <alias[@instance]>",
+		Short: "Show alias container logs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			opts.Stdout = os.Stdout
+			opts.Stderr = os.Stderr
+			return app.Svc.Logs(ctx, target, here, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "show logs for the current project directory")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "follow log output")
+	cmd.Flags().StringVarP(&opts.Tail, "tail", "n", "all", "number of lines to show from the end")
+	cmd.Flags().BoolVarP(&opts.Timestamps, "timestamps", "t", false, "show timestamps")
+	return cmd
+}
+
+func NewCommitCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+
+	cmd := &cobra.Command{
+		Use:   "commit <alias[@instance]> [tag]",
+		Short: "Save an alias container as a snapshot image",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			tag := ""
+			if len(args) > 1 {
+				tag = args[1]
+			}
+			ref, err := app.Svc.Commit(context.Background(), target, here, tag)
+			if err != nil {
+				return err
+			}
+			app.Renderer.SnapshotCreated(ref)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "commit the container for the current project directory")
+	return cmd
+}
+
+func NewLockCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var update []string
+
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Pin pull images and build base images to digests in cradle.lock",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			lock, err := app.Svc.Lock(cmd.Context(), update)
+			if err != nil {
+				return err
+			}
+			app.Renderer.LockWritten(service.LockPath(app.Cfg), lock, update)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&update, "update", nil, "refresh only this alias (repeatable)")
+	return cmd
+}
+
+func NewSaveCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "save <alias|all> -o bundle.tar",
+		Short: "Write alias images and config to a bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			names := []string{args[0]}
+			if args[0] == "all" {
+				names = names[:0]
+				for _, info := range app.Svc.ListAliases() {
+					names = append(names, info.Name)
+				}
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			manifest, err := app.Svc.Save(cmd.Context(), names, f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return err
+			}
+			app.Renderer.BundleSaved(output, manifest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "bundle file to write")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func NewLoadCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var configOut string
+
+	cmd := &cobra.Command{
+		Use:   "load <bundle.tar>",
+		Short: "Load alias images from a bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			manifest, err := app.Svc.Load(cmd.Context(), f, os.Stdout)
+			if err != nil {
+				return err
+			}
+			if configOut != "" {
+				if err = os.WriteFile(configOut, manifest.Config, 0o600); err != nil {
+					return err
+				}
+			}
+			app.Renderer.BundleLoaded(manifest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&configOut, "config-out", "", "write the bundled config to this file")
+	return cmd
+}
+
+func NewOutdatedCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var all bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List pull aliases whose registry image changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			items, err := app.Svc.Outdated(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return app.Renderer.OutdatedJSON(items)
+			}
+			app.Renderer.Outdated(items, all)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "show every pull alias, not just outdated ones")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print results as JSON")
+	return cmd
+}
+
+func NewPortCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "port <alias[@instance]>",
+		Short: "List published ports of an alias container",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			ports, err := app.Svc.Ports(context.Background(), target, here)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return app.Renderer.PortsJSON(ports)
+			}
+			app.Renderer.PortList(ports)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "show ports for the current project directory")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print ports as JSON")
+	return cmd
+}
+
+func NewOpenCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open <alias[@instance]> [port]",
+		Short: "Open a published alias port in the host browser",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			ports, err := app.Svc.Ports(ctx, target, here)
+			if err != nil {
+				return err
+			}
+			containerPort := ""
+			if len(args) > 1 {
+				containerPort = args[1]
+			}
+			url, err := service.SelectURL(ports, containerPort)
+			if err != nil {
+				return err
+			}
+			if printOnly {
+				app.Renderer.URL(url)
+				return nil
+			}
+			if openErr := service.OpenURL(ctx, url); openErr != nil {
+				if !errors.Is(openErr, service.ErrNoURLOpener) {
+					return openErr
+				}
+				log.Warn("cannot open url", "error", openErr)
+				app.Renderer.URL(url)
+				return nil
+			}
+			log.Info("opened url", "url", url)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "open the container for the current project directory")
+	cmd.Flags().BoolVarP(&printOnly, "print", "p", false, "print the URL instead of opening it")
+	return cmd
+}
+
+func NewDoctorCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the Docker engine, config and host requirements",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path := *cfgPath
+			if path == "" {
+				path = DefaultConfigPath()
+			}
+			cfg, configCheck := service.CheckConfig(path)
+			if cfg == nil {
+				cfg = &config.Config{}
+			}
+			checks := []service.Check{configCheck}
+
+			svc, err := service.New(cfg)
+			if err != nil {
+				checks = append(checks, service.Check{
+					Name:   "engine",
+					Status: service.CheckFail,
+					Detail: err.Error(),
+					Fix:    "check DOCKER_HOST and the other DOCKER_* environment variables",
+				})
+				checks = append(checks, service.DirChecks()...)
+			} else {
+				svc.SetLogger(log)
+				defer func() {
+					if closeErr := svc.Close(); closeErr != nil {
+						log.Warn("service close failed", "error", closeErr)
+					}
+				}()
+				checks = append(checks, svc.Doctor(cmd.Context())...)
+			}
+
+			renderer := render.New(log, os.Stdout)
+			if jsonOut {
+				if jsonErr := renderer.DoctorJSON(checks); jsonErr != nil {
+					return jsonErr
+				}
+			} else {
+				renderer.Doctor(checks)
+			}
+			if failed := service.FailedChecks(checks); failed > 0 {
+				return fmt.Errorf("doctor found %d failing checks", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print checks as JSON")
+	return cmd
+}
+
+func NewExecCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	var here bool
+
+	cmd := &cobra.Command{
+		Use:   "exec <alias[@instance]> [-- command...]",
+		Short: "Run a command in a running alias container",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			app, err := NewApp(*cfgPath, log)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := app.Svc.Close(); closeErr != nil {
+					log.Warn("service close failed", "error", closeErr)
+				}
+			}()
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+			defer stop()
+
+			target, err := service.ParseTarget(args[0])
+			if err != nil {
+				return err
+			}
+			// Flags stop at the alias, so cobra keeps the "--" separator in args.
+			command := args[1:]
+			if len(command) > 0 && command[0] == "--" {
+				command = command[1:]
+			}
+			code, err := app.Svc.Exec(ctx, target, here, service.ExecOptions{
+				Cmd:    command,
+				Stdin:  os.Stdin,
+				Stdout: os.Stdout,
+				Stderr: os.Stderr,
+			})
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				return fmt.Errorf("command exited with code %d", code)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().BoolVar(&here, "here", false, "exec in the container for the current project directory")
+	return cmd
+}
+
+func NewConfigCmd(cfgPath *string, log *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Validate config and check host requirements",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			path := *cfgPath
+			if path == "" {
+				path = DefaultConfigPath()
+			}
+			cfg, err := config.LoadFile(path)
+			if err != nil {
+				return err
+			}
+
+			problems := service.NewWithClient(cfg, nil).CheckDisplays()
+			for _, problem := range problems {
+				log.Warn("display unavailable", "error", problem)
+			}
+			log.Info("config valid", "path", path, "aliases", len(cfg.Aliases), "warnings", len(problems))
+			return nil
+		},
+	})
+	return cmd
 }
@@ -1,9 +1,16 @@
 package cli_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/cli"
@@ -37,6 +44,38 @@ aliases:
 	_ = app.Svc.Close()
 }
 
+func TestConfigValidateWarnsOnMissingDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+aliases:
+  gui:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      display: x11
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	cmd := cli.NewConfigCmd(&cfgPath, log)
+	cmd.SetArgs([]string{"validate"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("config validate error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "aliases.gui.run.display") || !strings.Contains(out, "DISPLAY is not set") {
+		t.Fatalf("expected display warning, got %q", out)
+	}
+}
+
 func TestCommandRunEConfigError(t *testing.T) {
 	log := slog.New(slog.DiscardHandler)
 	cfgPath := "/nonexistent/config.yaml"
@@ -60,6 +99,62 @@ func TestCommandRunEConfigError(t *testing.T) {
 	if err := stopCmd.RunE(stopCmd, []string{"demo"}); err == nil {
 		t.Fatalf("expected stop command to fail with bad config path")
 	}
+
+	rmCmd := cli.NewRmCmd(&cfgPath, log)
+	if err := rmCmd.RunE(rmCmd, []string{"demo@b"}); err == nil {
+		t.Fatalf("expected rm command to fail with bad config path")
+	}
+
+	logsCmd := cli.NewLogsCmd(&cfgPath, log)
+	if err := logsCmd.RunE(logsCmd, []string{"demo@b"}); err == nil {
+		t.Fatalf("expected logs command to fail with bad config path")
+	}
+
+	execCmd := cli.NewExecCmd(&cfgPath, log)
+	if err := execCmd.RunE(execCmd, []string{"demo@b", "true"}); err == nil {
+		t.Fatalf("expected exec command to fail with bad config path")
+	}
+
+	commitCmd := cli.NewCommitCmd(&cfgPath, log)
+	if err := commitCmd.RunE(commitCmd, []string{"demo", "mine"}); err == nil {
+		t.Fatalf("expected commit command to fail with bad config path")
+	}
+
+	outdatedCmd := cli.NewOutdatedCmd(&cfgPath, log)
+	if err := outdatedCmd.RunE(outdatedCmd, nil); err == nil {
+		t.Fatalf("expected outdated command to fail with bad config path")
+	}
+
+	lockCmd := cli.NewLockCmd(&cfgPath, log)
+	if err := lockCmd.RunE(lockCmd, nil); err == nil {
+		t.Fatalf("expected lock command to fail with bad config path")
+	}
+
+	saveCmd := cli.NewSaveCmd(&cfgPath, log)
+	if err := saveCmd.RunE(saveCmd, []string{"all"}); err == nil {
+		t.Fatalf("expected save command to fail with bad config path")
+	}
+
+	loadCmd := cli.NewLoadCmd(&cfgPath, log)
+	if err := loadCmd.RunE(loadCmd, []string{"bundle.tar"}); err == nil {
+		t.Fatalf("expected load command to fail with bad config path")
+	}
+
+	upCmd := cli.NewUpCmd(&cfgPath, log)
+	if err := upCmd.RunE(upCmd, []string{"backend"}); err == nil {
+		t.Fatalf("expected up command to fail with bad config path")
+	}
+
+	downCmd := cli.NewDownCmd(&cfgPath, log)
+	if err := downCmd.RunE(downCmd, []string{"backend"}); err == nil {
+		t.Fatalf("expected down command to fail with bad config path")
+	}
+
+	configCmd := cli.NewConfigCmd(&cfgPath, log)
+	configCmd.SetArgs([]string{"validate"})
+	if err := configCmd.Execute(); err == nil {
+		t.Fatalf("expected config validate to fail with bad config path")
+	}
 }
 
 func TestCommandFlags(t *testing.T) {
@@ -81,4 +176,142 @@ func TestCommandFlags(t *testing.T) {
 	if runCmd.Flags().Lookup("pull") == nil {
 		t.Fatalf("expected pull flag on run command")
 	}
+	if runCmd.Flags().Lookup("here") == nil {
+		t.Fatalf("expected here flag on run command")
+	}
+	if runCmd.Flags().Lookup("json") == nil {
+		t.Fatalf("expected json flag on run command")
+	}
+
+	stopCmd := cli.NewStopCmd(&cfgPath, log)
+	if stopCmd.Flags().Lookup("here") == nil {
+		t.Fatalf("expected here flag on stop command")
+	}
+
+	rmCmd := cli.NewRmCmd(&cfgPath, log)
+	if rmCmd.Flags().Lookup("force") == nil {
+		t.Fatalf("expected force flag on rm command")
+	}
+
+	logsCmd := cli.NewLogsCmd(&cfgPath, log)
+	for _, name := range []string{"follow", "tail", "timestamps"} {
+		if logsCmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected %s flag on logs command", name)
+		}
+	}
+
+	portCmd := cli.NewPortCmd(&cfgPath, log)
+	if portCmd.Flags().Lookup("json") == nil {
+		t.Fatalf("expected json flag on port command")
+	}
+	openCmd := cli.NewOpenCmd(&cfgPath, log)
+	if openCmd.Flags().Lookup("print") == nil {
+		t.Fatalf("expected print flag on open command")
+	}
+}
+
+// writeDemoConfig writes a config with a single pull alias named demo.
+func writeDemoConfig(t *testing.T) string {
+	t.Helper()
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "version: 1\naliases:\n  demo:\n    image:\n      pull:\n        ref: ubuntu:24.04\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return cfgPath
+}
+
+// useFakeDaemon points the Docker client of NewApp at handler.
+func useFakeDaemon(t *testing.T, handler http.Handler) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(srv.URL, "http://"))
+	t.Setenv("DOCKER_API_VERSION", "1.52")
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+}
+
+func TestExecCmdStripsDashSeparator(t *testing.T) {
+	var got []string
+	useFakeDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			var body struct{ Cmd []string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			got = body.Cmd
+			http.Error(w, `{"message":"stop here"}`, http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"Id": "abc", "State": map[string]any{"Running": true}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	cfgPath := writeDemoConfig(t)
+	cmd := cli.NewExecCmd(&cfgPath, slog.New(slog.DiscardHandler))
+	cmd.SetArgs([]string{"demo", "--", "ls", "-la"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	_ = cmd.Execute()
+	if want := []string{"ls", "-la"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("exec command = %q, want %q", got, want)
+	}
+}
+
+// captureStdout runs fn with os.Stdout redirected and returns what it printed.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	_ = w.Close()
+	return string(<-done)
+}
+
+func TestRunCmdJSONKeepsStdoutClean(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	useFakeDaemon(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/create"):
+			_, _ = io.WriteString(w, `{"status":"Status: Downloaded newer image for ubuntu:24.04"}`+"\n")
+		case strings.HasSuffix(r.URL.Path, "/images/ubuntu:24.04/json"):
+			_, _ = io.WriteString(w, `{"Id":"sha256:img","Config":{}}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/create"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"Id":"abc"}`)
+		case strings.HasSuffix(r.URL.Path, "/containers/abc/start"):
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/containers/abc/json"):
+			_, _ = io.WriteString(w, `{"Id":"abc","State":{"Running":true}}`)
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+
+	cfgPath := writeDemoConfig(t)
+	cmd := cli.NewRunCmd(&cfgPath, slog.New(slog.DiscardHandler))
+	cmd.SetArgs([]string{"demo", "--json"})
+	var err error
+	out := captureStdout(t, func() { err = cmd.Execute() })
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	dec := json.NewDecoder(strings.NewReader(out))
+	var result map[string]any
+	if err = dec.Decode(&result); err != nil {
+		t.Fatalf("expected JSON on stdout, got %q: %v", out, err)
+	}
+	if result["id"] != "abc" || dec.More() {
+		t.Fatalf("expected exactly one JSON value on stdout, got %q", out)
+	}
 }
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CacheType selects where build cache is imported from or exported to.
+type CacheType string
+
+const (
+	CacheTypeRegistry CacheType = "registry"
+	CacheTypeLocal    CacheType = "local"
+	CacheTypeInline   CacheType = "inline"
+)
+
+// CacheSpec is a cache_from or cache_to entry. It accepts the buildx-style
+// "type=local,src=path" string; a string without "=" is a registry ref.
+type CacheSpec struct {
+	Type CacheType `json:"type,omitempty" yaml:"type,omitempty"` // default: registry
+	Ref  string    `json:"ref,omitempty"  yaml:"ref,omitempty"`  // registry image that holds the cache
+	// Src and Dest are the local cache directories for cache_from and cache_to;
+	// both default to the alias directory under $XDG_CACHE_HOME/cradle/buildcache.
+	Src  string `json:"src,omitempty"  yaml:"src,omitempty"`
+	Dest string `json:"dest,omitempty" yaml:"dest,omitempty"`
+
+	// short holds the string form until validateCaches parses it.
+	short string
+}
+
+type cacheSpecFields CacheSpec
+
+// UnmarshalYAML accepts the mapping form or the "type=...,key=value" string.
+func (c *CacheSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = CacheSpec{}
+		return value.Decode(&c.short)
+	}
+	var fields cacheSpecFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*c = CacheSpec(fields)
+	return nil
+}
+
+func (c *CacheSpec) UnmarshalJSON(data []byte) error {
+	var short string
+	if err := json.Unmarshal(data, &short); err == nil {
+		*c = CacheSpec{short: short}
+		return nil
+	}
+	var fields cacheSpecFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*c = CacheSpec(fields)
+	return nil
+}
+
+// parseShortCache parses "type=local,src=path" style entries; a value without "="
+// is a registry ref.
+func parseShortCache(raw string) (CacheSpec, error) {
+	if !strings.Contains(raw, "=") {
+		return CacheSpec{Type: CacheTypeRegistry, Ref: raw}, nil
+	}
+	var c CacheSpec
+	for field := range strings.SplitSeq(raw, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return CacheSpec{}, fmt.Errorf("want key=value, got %q", field)
+		}
+		switch key {
+		case "type":
+			c.Type = CacheType(value)
+		case "ref":
+			c.Ref = value
+		case "src":
+			c.Src = value
+		case "dest":
+			c.Dest = value
+		default:
+			return CacheSpec{}, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return c, nil
+}
+
+// validateCaches parses shorthand entries and checks each one. field is
+// cache_from or cache_to.
+func validateCaches(name, field string, caches []CacheSpec, baseDir string) error {
+	for i := range caches {
+		path := fmt.Sprintf("aliases.%s.image.build.%s[%d]", name, field, i)
+		if caches[i].short != "" {
+			parsed, err := parseShortCache(caches[i].short)
+			if err != nil {
+				return fmt.Errorf("%s: invalid %q: %w", path, caches[i].short, err)
+			}
+			caches[i] = parsed
+		}
+		if err := validateCache(path, &caches[i], field == "cache_to"); err != nil {
+			return err
+		}
+		caches[i].Src = resolvePath(baseDir, caches[i].Src)
+		caches[i].Dest = resolvePath(baseDir, caches[i].Dest)
+	}
+	return nil
+}
+
+// validateCache applies the registry default and checks the fields each type needs.
+func validateCache(path string, c *CacheSpec, export bool) error {
+	if c.Type == "" {
+		c.Type = CacheTypeRegistry
+	}
+	switch c.Type {
+	case CacheTypeRegistry:
+		if c.Ref == "" {
+			return fmt.Errorf("%s.ref: required for registry cache", path)
+		}
+	case CacheTypeLocal, CacheTypeInline:
+		if c.Type == CacheTypeInline && !export {
+			return fmt.Errorf("%s: inline cache is read through a registry ref", path)
+		}
+		if c.Ref != "" {
+			return fmt.Errorf("%s.ref: only valid for registry cache", path)
+		}
+	default:
+		return fmt.Errorf("%s.type: must be registry|local|inline", path)
+	}
+	if (c.Src != "" || c.Dest != "") && c.Type != CacheTypeLocal {
+		return fmt.Errorf("%s: src and dest are only valid for local cache", path)
+	}
+	if export && c.Src != "" {
+		return fmt.Errorf("%s.src: cache_to writes to dest", path)
+	}
+	if !export && c.Dest != "" {
+		return fmt.Errorf("%s.dest: cache_from reads from src", path)
+	}
+	return nil
+}
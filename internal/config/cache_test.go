@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+func TestLoadFileBuildCache(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+aliases:
+  demo:
+    image:
+      build:
+        cwd: .
+        cache_from:
+          - ghcr.io/org/app:cache
+          - type=local,src=./cache
+          - type: local
+        cache_to:
+          - type=inline
+          - type: local
+            dest: ./cache
+          - type=registry,ref=ghcr.io/org/app:cache
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	build := cfg.Aliases["demo"].Image.Build
+	from := build.CacheFrom
+	if len(from) != 3 {
+		t.Fatalf("expected 3 cache_from entries, got %+v", from)
+	}
+	if from[0].Type != config.CacheTypeRegistry || from[0].Ref != "ghcr.io/org/app:cache" {
+		t.Fatalf("unexpected registry shorthand: %+v", from[0])
+	}
+	if from[1].Type != config.CacheTypeLocal || from[1].Src != filepath.Join(dir, "cache") {
+		t.Fatalf("unexpected local shorthand: %+v", from[1])
+	}
+	if from[2].Type != config.CacheTypeLocal || from[2].Src != "" {
+		t.Fatalf("expected default local source, got %+v", from[2])
+	}
+	to := build.CacheTo
+	if len(to) != 3 || to[0].Type != config.CacheTypeInline || to[1].Dest != filepath.Join(dir, "cache") ||
+		to[2].Type != config.CacheTypeRegistry || to[2].Ref != "ghcr.io/org/app:cache" {
+		t.Fatalf("unexpected cache_to: %+v", to)
+	}
+}
+
+func TestValidateBuildCacheInvalid(t *testing.T) {
+	cases := map[string]string{
+		`{"cache_from": ["type=registry"]}`:                 "cache_from[0].ref",
+		`{"cache_from": ["type=inline"]}`:                   "cache_from[0]",
+		`{"cache_from": ["type=local,dest=/tmp/c"]}`:        "cache_from[0].dest",
+		`{"cache_from": ["type=gha"]}`:                      "cache_from[0].type",
+		`{"cache_from": ["type=local,mode=max"]}`:           "cache_from[0]",
+		`{"cache_to": ["ghcr.io/a:c", "type=local,src=x"]}`: "cache_to[1].src",
+		`{"cache_to": [{"type": "inline", "ref": "a:b"}]}`:  "cache_to[0].ref",
+		`{"cache_to": [{"type": "registry", "dest": "x"}]}`: "cache_to[0]",
+	}
+	for raw, field := range cases {
+		build := &config.BuildSpec{}
+		if err := json.Unmarshal([]byte(raw), build); err != nil {
+			t.Fatalf("unmarshal %s: %v", raw, err)
+		}
+		build.Cwd = t.TempDir()
+		cfg := &config.Config{Aliases: map[string]config.Alias{"demo": {Image: config.ImageSpec{Build: build}}}}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), field) {
+			t.Fatalf("%s: expected %s error, got %v", raw, field, err)
+		}
+	}
+}
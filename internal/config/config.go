@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/docker/go-units"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,19 +19,59 @@ type Config struct {
 	// BaseDir is the directory containing the config file; useful for resolving relative paths.
 	BaseDir string `json:"-" yaml:"-"`
 
-	Version int              `json:"version" yaml:"version"`
+	Version int `json:"version" yaml:"version"`
+
+	// Networks and Volumes declare Docker resources that aliases may reference.
+	// Non-external ones are created on demand by run.
+	Networks map[string]DeclaredNetworkSpec `json:"networks,omitempty" yaml:"networks,omitempty"`
+	Volumes  map[string]DeclaredVolumeSpec  `json:"volumes,omitempty"  yaml:"volumes,omitempty"`
+
 	Aliases map[string]Alias `json:"aliases" yaml:"aliases"`
+
+	// Groups name sets of aliases that `cradle up` and `cradle down` manage together.
+	Groups map[string][]string `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// portable skips validation against the local filesystem.
+	portable bool
+}
+
+// DeclaredNetworkSpec is a top-level network. External networks must already exist.
+type DeclaredNetworkSpec struct {
+	Driver   string            `json:"driver,omitempty"   yaml:"driver,omitempty"` // default: bridge
+	Options  map[string]string `json:"options,omitempty"  yaml:"options,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"   yaml:"labels,omitempty"`
+	Internal bool              `json:"internal,omitempty" yaml:"internal,omitempty"`
+	External bool              `json:"external,omitempty" yaml:"external,omitempty"`
+}
+
+// DeclaredVolumeSpec is a top-level named volume. External volumes must already exist.
+type DeclaredVolumeSpec struct {
+	Driver   string            `json:"driver,omitempty"   yaml:"driver,omitempty"` // default: local
+	Options  map[string]string `json:"options,omitempty"  yaml:"options,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"   yaml:"labels,omitempty"`
+	External bool              `json:"external,omitempty" yaml:"external,omitempty"`
 }
 
 type Alias struct {
 	Image ImageSpec `json:"image" yaml:"image"`
 	Run   RunSpec   `json:"run"   yaml:"run"`
+
+	// Host commands run through /bin/sh from BaseDir around build and run.
+	PreBuild  []string `json:"pre_build,omitempty"  yaml:"pre_build,omitempty"`
+	PostBuild []string `json:"post_build,omitempty" yaml:"post_build,omitempty"`
+	PreRun    []string `json:"pre_run,omitempty"    yaml:"pre_run,omitempty"`
+	PostRun   []string `json:"post_run,omitempty"   yaml:"post_run,omitempty"`
+
+	// DependsOn lists aliases that `cradle up` starts (and waits for) before this one.
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
 
 type ImageSpec struct {
 	// Exactly one of Pull or Build should be set.
 	Pull  *PullSpec  `json:"pull,omitempty"  yaml:"pull,omitempty"`
 	Build *BuildSpec `json:"build,omitempty" yaml:"build,omitempty"`
+	// Snapshot runs the newest `cradle commit` snapshot of the alias when one exists.
+	Snapshot bool `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
 }
 
 type ImagePolicy string
@@ -47,19 +88,34 @@ type PullSpec struct {
 	Platform string            `json:"platform,omitempty" yaml:"platform,omitempty"`
 	Auth     *RegistryAuthSpec `json:"auth,omitempty"     yaml:"auth,omitempty"`
 	// e.g. ubuntu:24.04
+
+	// policyDefaulted is set when Validate filled in Policy.
+	policyDefaulted bool
 }
 
-type BuildSpec struct {
-	Cwd        string            `json:"cwd"                  yaml:"cwd"`                  // context root (your “cwd”)
-	Dockerfile string            `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"` // default: Dockerfile
-	Args       map[string]string `json:"args,omitempty"       yaml:"args,omitempty"`
-	Target     string            `json:"target,omitempty"     yaml:"target,omitempty"`
-	Labels     map[string]string `json:"labels,omitempty"     yaml:"labels,omitempty"`
-	Policy     ImagePolicy       `json:"policy,omitempty"     yaml:"policy,omitempty"`
+// PolicyDefaulted reports whether Policy is the default rather than set in the config.
+func (p *PullSpec) PolicyDefaulted() bool {
+	return p.policyDefaulted
+}
 
-	PullParent bool     `json:"pull,omitempty"       yaml:"pull,omitempty"` // maps to PullParent
-	NoCache    bool     `json:"no_cache,omitempty"   yaml:"no_cache,omitempty"`
-	CacheFrom  []string `json:"cache_from,omitempty" yaml:"cache_from,omitempty"`
+type BuildSpec struct {
+	Cwd        string `json:"cwd"                  yaml:"cwd"`                  // context root (your “cwd”)
+	Dockerfile string `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"` // default: Dockerfile
+	// DockerfileInline is used instead of Dockerfile; cwd is optional.
+	DockerfileInline string `json:"dockerfile_inline,omitempty" yaml:"dockerfile_inline,omitempty"`
+	// Recipe generates the Dockerfile; cwd is optional.
+	Recipe *RecipeSpec `json:"recipe,omitempty" yaml:"recipe,omitempty"`
+	// Git clones a repository into the local cache and uses it as the context instead of cwd.
+	Git    *GitContextSpec   `json:"git,omitempty" yaml:"git,omitempty"`
+	Args   map[string]string `json:"args,omitempty"       yaml:"args,omitempty"`
+	Target string            `json:"target,omitempty"     yaml:"target,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"     yaml:"labels,omitempty"`
+	Policy ImagePolicy       `json:"policy,omitempty"     yaml:"policy,omitempty"`
+
+	PullParent bool        `json:"pull,omitempty"       yaml:"pull,omitempty"` // maps to PullParent
+	NoCache    bool        `json:"no_cache,omitempty"   yaml:"no_cache,omitempty"`
+	CacheFrom  []CacheSpec `json:"cache_from,omitempty" yaml:"cache_from,omitempty"`
+	CacheTo    []CacheSpec `json:"cache_to,omitempty"   yaml:"cache_to,omitempty"`
 
 	Tags           []string                    `json:"tags,omitempty"            yaml:"tags,omitempty"`
 	SuppressOutput bool                        `json:"suppress_output,omitempty" yaml:"suppress_output,omitempty"`
@@ -87,6 +143,21 @@ type BuildSpec struct {
 	ExtraHosts []string `json:"extra_hosts,omitempty" yaml:"extra_hosts,omitempty"` // ["host.docker.internal:host-gateway"]
 
 	Platforms []string `json:"platforms,omitempty" yaml:"platforms,omitempty"` // e.g. ["linux/amd64"]
+
+	// policyDefaulted is set when Validate filled in Policy.
+	policyDefaulted bool
+}
+
+// PolicyDefaulted reports whether Policy is the default rather than set in the config.
+func (b *BuildSpec) PolicyDefaulted() bool {
+	return b.policyDefaulted
+}
+
+// GitContextSpec is a git repository used as a build context.
+type GitContextSpec struct {
+	URL    string `json:"url"              yaml:"url"`
+	Ref    string `json:"ref,omitempty"    yaml:"ref,omitempty"`    // branch, tag or commit; default: HEAD
+	Subdir string `json:"subdir,omitempty" yaml:"subdir,omitempty"` // context directory inside the repository
 }
 
 type UlimitSpec struct {
@@ -114,6 +185,8 @@ type RunSpec struct {
 	UID  int    `json:"uid,omitempty"  yaml:"uid,omitempty"`
 	GID  int    `json:"gid,omitempty"  yaml:"gid,omitempty"`
 	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// HostUser mirrors the caller's uid, gid, username, home, shell and groups into the container.
+	HostUser bool `json:"host_user,omitempty" yaml:"host_user,omitempty"`
 
 	// UX defaults for interactive shells
 	TTY        *bool `json:"tty,omitempty"         yaml:"tty,omitempty"`         // default false if nil
@@ -134,6 +207,7 @@ type RunSpec struct {
 	Networks    map[string]NetworkSpec `json:"networks,omitempty"     yaml:"networks,omitempty"`
 	Ports       []string               `json:"ports,omitempty"        yaml:"ports,omitempty"` // ["8080:80", "127.0.0.1:2222:22"]
 	Expose      []string               `json:"expose,omitempty"       yaml:"expose,omitempty"`
+	URLs        map[string]PortURLSpec `json:"urls,omitempty"         yaml:"urls,omitempty"` // keyed by container port
 	ExtraHosts  []string               `json:"extra_hosts,omitempty"  yaml:"extra_hosts,omitempty"`
 	DNS         []string               `json:"dns,omitempty"          yaml:"dns,omitempty"`
 	DNSSearch   []string               `json:"dns_search,omitempty"   yaml:"dns_search,omitempty"`
@@ -143,7 +217,10 @@ type RunSpec struct {
 	UTS         string                 `json:"uts,omitempty"          yaml:"uts,omitempty"`
 	Runtime     string                 `json:"runtime,omitempty"      yaml:"runtime,omitempty"`
 
-	Volumes []MountSpec `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Volumes  []MountSpec   `json:"volumes,omitempty"   yaml:"volumes,omitempty"`
+	MountCwd *MountCwdSpec `json:"mount_cwd,omitempty" yaml:"mount_cwd,omitempty"` // true or {target, git_root, read_only}
+	Forward  []ForwardKind `json:"forward,omitempty"   yaml:"forward,omitempty"`   // ["ssh-agent", "gpg-agent", "gitconfig"]
+	Display  DisplayMode   `json:"display,omitempty"   yaml:"display,omitempty"`   // x11|wayland|auto
 
 	Resources       *ResourcesSpec    `json:"resources,omitempty"         yaml:"resources,omitempty"`
 	Privileged      bool              `json:"privileged,omitempty"        yaml:"privileged,omitempty"`
@@ -167,6 +244,22 @@ type RunSpec struct {
 	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"` // optional override, e.g. linux/amd64
 }
 
+type ForwardKind string
+
+const (
+	ForwardSSHAgent  ForwardKind = "ssh-agent"
+	ForwardGPGAgent  ForwardKind = "gpg-agent"
+	ForwardGitConfig ForwardKind = "gitconfig"
+)
+
+type DisplayMode string
+
+const (
+	DisplayX11     DisplayMode = "x11"
+	DisplayWayland DisplayMode = "wayland"
+	DisplayAuto    DisplayMode = "auto"
+)
+
 type GPURequestSpec struct {
 	Capabilities []string          `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
 	Driver       string            `json:"driver,omitempty"       yaml:"driver,omitempty"`
@@ -230,6 +323,60 @@ func parseDeviceCount(raw string) (DeviceCount, error) {
 	return DeviceCount(n), nil
 }
 
+const DefaultMountCwdTarget = "/workspace"
+
+// PortURLSpec describes how to reach a published container port from a browser.
+type PortURLSpec struct {
+	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"` // default: http
+	Path   string `json:"path,omitempty"   yaml:"path,omitempty"`   // e.g. "/"
+}
+
+// MountCwdSpec binds the invoking directory (or its git root) into the container.
+// It accepts a bool shorthand; the mapping form is enabled unless enabled is false.
+type MountCwdSpec struct {
+	Enabled  bool   `json:"enabled,omitempty"   yaml:"enabled,omitempty"`
+	Target   string `json:"target,omitempty"    yaml:"target,omitempty"`   // default: /workspace
+	GitRoot  *bool  `json:"git_root,omitempty"  yaml:"git_root,omitempty"` // default true if nil
+	ReadOnly bool   `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+}
+
+type mountCwdFields MountCwdSpec
+
+func (m *MountCwdSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Enabled)
+	}
+	fields := mountCwdFields{Enabled: true}
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*m = MountCwdSpec(fields)
+	return nil
+}
+
+// MarshalYAML writes a disabled mount as false, since the mapping form defaults
+// enabled to true.
+func (m MountCwdSpec) MarshalYAML() (any, error) {
+	if !m.Enabled {
+		return false, nil
+	}
+	return mountCwdFields(m), nil
+}
+
+func (m *MountCwdSpec) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*m = MountCwdSpec{Enabled: enabled}
+		return nil
+	}
+	fields := mountCwdFields{Enabled: true}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*m = MountCwdSpec(fields)
+	return nil
+}
+
 type NetworkSpec struct {
 	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
 }
@@ -240,6 +387,115 @@ type MountSpec struct {
 	Source   string `json:"source,omitempty"    yaml:"source,omitempty"`
 	Target   string `json:"target"              yaml:"target"`
 	ReadOnly bool   `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+	// Create decides what happens when a bind source does not exist: true creates the
+	// directory before run, false fails validation. Unset leaves it to Docker.
+	Create *bool `json:"create,omitempty" yaml:"create,omitempty"`
+
+	// Type-specific options; each is only valid for its own type.
+	Bind   *BindOptionsSpec   `json:"bind,omitempty"   yaml:"bind,omitempty"`
+	Volume *VolumeOptionsSpec `json:"volume,omitempty" yaml:"volume,omitempty"`
+	Tmpfs  *TmpfsOptionsSpec  `json:"tmpfs,omitempty"  yaml:"tmpfs,omitempty"`
+
+	// short holds the "source:target[:options]" form until validateMounts parses it.
+	short string
+}
+
+type mountSpecFields MountSpec
+
+// UnmarshalYAML accepts the mapping form or the short "source:target[:options]" string.
+func (m *MountSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*m = MountSpec{}
+		return value.Decode(&m.short)
+	}
+	var fields mountSpecFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*m = MountSpec(fields)
+	return nil
+}
+
+func (m *MountSpec) UnmarshalJSON(data []byte) error {
+	var short string
+	if err := json.Unmarshal(data, &short); err == nil {
+		*m = MountSpec{short: short}
+		return nil
+	}
+	var fields mountSpecFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*m = MountSpec(fields)
+	return nil
+}
+
+// parseShortMount parses "source:target[:options]". Sources starting with "/", "." or
+// "~" are binds, anything else is a named volume. Options are comma-separated:
+// ro, rw, nocopy (volumes) and a bind propagation mode.
+func parseShortMount(raw string) (MountSpec, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return MountSpec{}, errors.New("want source:target[:options]")
+	}
+	m := MountSpec{Type: "volume", Source: parts[0], Target: parts[1]}
+	if strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~") {
+		m.Type = "bind"
+	}
+	if len(parts) < 3 {
+		return m, nil
+	}
+	for opt := range strings.SplitSeq(parts[2], ",") {
+		switch {
+		case opt == "ro":
+			m.ReadOnly = true
+		case opt == "rw":
+		case opt == "nocopy" && m.Type == "volume":
+			m.Volume = &VolumeOptionsSpec{NoCopy: true}
+		case isPropagation(opt) && m.Type == "bind":
+			m.Bind = &BindOptionsSpec{Propagation: opt}
+		default:
+			return MountSpec{}, fmt.Errorf("unknown option %q for %s", opt, m.Type)
+		}
+	}
+	return m, nil
+}
+
+func isPropagation(value string) bool {
+	switch value {
+	case "rprivate", "private", "rshared", "shared", "rslave", "slave":
+		return true
+	}
+	return false
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func expandHome(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~")), nil
+}
+
+type BindOptionsSpec struct {
+	Propagation    string `json:"propagation,omitempty"      yaml:"propagation,omitempty"` // rprivate|private|rshared|shared|rslave|slave
+	CreateHostPath bool   `json:"create_host_path,omitempty" yaml:"create_host_path,omitempty"`
+}
+
+type VolumeOptionsSpec struct {
+	NoCopy  bool              `json:"nocopy,omitempty"  yaml:"nocopy,omitempty"`
+	Subpath string            `json:"subpath,omitempty" yaml:"subpath,omitempty"`
+	Driver  string            `json:"driver,omitempty"  yaml:"driver,omitempty"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"` // driver options
+}
+
+type TmpfsOptionsSpec struct {
+	Size string `json:"size,omitempty" yaml:"size,omitempty"` // e.g. "64m"
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"` // octal, e.g. "1777"
 }
 
 type ResourcesSpec struct {
@@ -288,11 +544,23 @@ func LoadFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("env expansion failed: %w", err)
 	}
 
-	cfg := &Config{
-		BaseDir: filepath.Dir(absPath),
-	}
+	return Parse([]byte(expanded), filepath.Dir(absPath))
+}
+
+// Parse decodes and validates config data whose environment variables are already
+// expanded. Relative paths resolve from baseDir.
+func Parse(data []byte, baseDir string) (*Config, error) {
+	return parse(&Config{BaseDir: baseDir}, data)
+}
+
+// ParsePortable decodes and validates a config written on another machine, such as
+// the one in a bundle. Checks against the local filesystem are skipped.
+func ParsePortable(data []byte) (*Config, error) {
+	return parse(&Config{portable: true}, data)
+}
 
-	dec := yaml.NewDecoder(bytes.NewReader([]byte(expanded)))
+func parse(cfg *Config, data []byte) (*Config, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
 	dec.KnownFields(true) // strict: unknown keys become errors
 
 	if decodeErr := dec.Decode(cfg); decodeErr != nil {
@@ -311,6 +579,10 @@ func LoadFile(path string) (*Config, error) {
 }
 
 func (c *Config) Validate() error {
+	if err := c.validateDeclared(); err != nil {
+		return err
+	}
+
 	for name, alias := range c.Aliases {
 		updated, err := c.validateAlias(name, alias)
 		if err != nil {
@@ -319,6 +591,79 @@ func (c *Config) Validate() error {
 		c.Aliases[name] = updated
 	}
 
+	if err := c.validateDependencies(); err != nil {
+		return err
+	}
+	if c.portable {
+		return nil
+	}
+	return c.validateBindSources()
+}
+
+// validateBindSources fails for bind sources with create: false that do not exist.
+func (c *Config) validateBindSources() error {
+	for name, alias := range c.Aliases {
+		for i, volume := range alias.Run.Volumes {
+			if volume.Type != "bind" || volume.Create == nil || *volume.Create {
+				continue
+			}
+			if _, err := os.Stat(volume.Source); errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf(
+					"aliases.%s.run.volumes[%d].source: %s does not exist; create it or set create: true",
+					name, i, volume.Source,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// IsBuiltinNetwork reports whether network is provided by Docker itself and needs no declaration.
+func IsBuiltinNetwork(network string) bool {
+	switch network {
+	case "", "default", "bridge", "host", "none":
+		return true
+	}
+	return strings.HasPrefix(network, "container:")
+}
+
+func (c *Config) validateDeclared() error {
+	for name, network := range c.Networks {
+		if IsBuiltinNetwork(name) {
+			return fmt.Errorf("networks.%s: %q is a built-in network", name, name)
+		}
+		if network.External && (network.Driver != "" || len(network.Options) > 0 || len(network.Labels) > 0) {
+			return fmt.Errorf("networks.%s: external networks cannot set driver, options or labels", name)
+		}
+	}
+	for name, volume := range c.Volumes {
+		if volume.External && (volume.Driver != "" || len(volume.Options) > 0 || len(volume.Labels) > 0) {
+			return fmt.Errorf("volumes.%s: external volumes cannot set driver, options or labels", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateReferences(name string, run RunSpec) error {
+	if _, ok := c.Networks[run.NetworkMode]; !ok && !IsBuiltinNetwork(run.NetworkMode) {
+		return fmt.Errorf("aliases.%s.run.network_mode: network %q is not declared in networks", name, run.NetworkMode)
+	}
+	for network := range run.Networks {
+		if _, ok := c.Networks[network]; !ok && !IsBuiltinNetwork(network) {
+			return fmt.Errorf("aliases.%s.run.networks.%s: network is not declared in networks", name, network)
+		}
+	}
+	for i, volume := range run.Volumes {
+		if volume.Type != "volume" {
+			continue
+		}
+		if _, ok := c.Volumes[volume.Source]; !ok {
+			return fmt.Errorf(
+				"aliases.%s.run.volumes[%d].source: volume %q is not declared in volumes",
+				name, i, volume.Source,
+			)
+		}
+	}
 	return nil
 }
 
@@ -331,9 +676,37 @@ func (c *Config) validateAlias(name string, alias Alias) (Alias, error) {
 		return alias, err
 	}
 
+	if err := c.validateReferences(name, alias.Run); err != nil {
+		return alias, err
+	}
+
+	if err := validateHooks(name, alias); err != nil {
+		return alias, err
+	}
+
 	return alias, nil
 }
 
+func validateHooks(name string, alias Alias) error {
+	hooks := []struct {
+		field    string
+		commands []string
+	}{
+		{field: "pre_build", commands: alias.PreBuild},
+		{field: "post_build", commands: alias.PostBuild},
+		{field: "pre_run", commands: alias.PreRun},
+		{field: "post_run", commands: alias.PostRun},
+	}
+	for _, hook := range hooks {
+		for i, command := range hook.commands {
+			if strings.TrimSpace(command) == "" {
+				return fmt.Errorf("aliases.%s.%s[%d]: command is required", name, hook.field, i)
+			}
+		}
+	}
+	return nil
+}
+
 func validateImage(name string, alias *Alias, baseDir string) error {
 	if alias.Image.Pull == nil && alias.Image.Build == nil {
 		return fmt.Errorf("aliases.%s.image: must specify either pull or build", name)
@@ -350,6 +723,9 @@ func validateImage(name string, alias *Alias, baseDir string) error {
 		if err != nil {
 			return fmt.Errorf("aliases.%s.image.pull.policy: %w", name, err)
 		}
+		if alias.Image.Pull.Policy == "" {
+			alias.Image.Pull.policyDefaulted = true
+		}
 		alias.Image.Pull.Policy = policy
 	}
 
@@ -360,22 +736,72 @@ func validateImage(name string, alias *Alias, baseDir string) error {
 	if err != nil {
 		return fmt.Errorf("aliases.%s.image.build.policy: %w", name, err)
 	}
+	if alias.Image.Build.Policy == "" {
+		alias.Image.Build.policyDefaulted = true
+	}
 	alias.Image.Build.Policy = policy
 
-	if alias.Image.Build.Cwd == "" && alias.Image.Build.RemoteContext == "" {
-		return fmt.Errorf("aliases.%s.image.build.cwd: required when remote_context is empty", name)
+	if err = validateBuildSource(name, alias.Image.Build, baseDir); err != nil {
+		return err
+	}
+	if err = validateCaches(name, "cache_from", alias.Image.Build.CacheFrom, baseDir); err != nil {
+		return err
+	}
+	if err = validateCaches(name, "cache_to", alias.Image.Build.CacheTo, baseDir); err != nil {
+		return err
 	}
 
 	if alias.Image.Build.Cwd != "" {
 		alias.Image.Build.Cwd = resolvePath(baseDir, alias.Image.Build.Cwd)
 	}
-	if alias.Image.Build.Dockerfile == "" {
+	if alias.Image.Build.Dockerfile == "" && alias.Image.Build.DockerfileInline == "" &&
+		alias.Image.Build.Recipe == nil {
 		alias.Image.Build.Dockerfile = "Dockerfile"
 	}
 
 	return nil
 }
 
+// validateBuildSource checks that the build has exactly one Dockerfile source and a
+// context when the Dockerfile comes from it.
+func validateBuildSource(name string, build *BuildSpec, baseDir string) error {
+	generated := build.DockerfileInline != "" || build.Recipe != nil
+	if build.DockerfileInline != "" && build.Recipe != nil {
+		return fmt.Errorf("aliases.%s.image.build: cannot specify both dockerfile_inline and recipe", name)
+	}
+	if generated && build.RemoteContext != "" {
+		return fmt.Errorf("aliases.%s.image.build: dockerfile_inline and recipe cannot use remote_context", name)
+	}
+	if generated && build.Dockerfile != "" {
+		return fmt.Errorf("aliases.%s.image.build.dockerfile: cannot be set with dockerfile_inline or recipe", name)
+	}
+	if build.Git != nil {
+		if err := validateGitContext(name, build); err != nil {
+			return err
+		}
+	} else if !generated && build.Cwd == "" && build.RemoteContext == "" {
+		return fmt.Errorf("aliases.%s.image.build.cwd: required when remote_context is empty", name)
+	}
+	if build.Recipe != nil {
+		return validateRecipe(name, build.Recipe, baseDir)
+	}
+	return nil
+}
+
+func validateGitContext(name string, build *BuildSpec) error {
+	if build.Git.URL == "" {
+		return fmt.Errorf("aliases.%s.image.build.git.url: required", name)
+	}
+	if build.Cwd != "" || build.RemoteContext != "" {
+		return fmt.Errorf("aliases.%s.image.build.git: cannot be combined with cwd or remote_context", name)
+	}
+	subdir := filepath.Clean(filepath.FromSlash(build.Git.Subdir))
+	if filepath.IsAbs(subdir) || subdir == ".." || strings.HasPrefix(subdir, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("aliases.%s.image.build.git.subdir: must be a relative path inside the repository", name)
+	}
+	return nil
+}
+
 func normalizeImagePolicy(value ImagePolicy, defaultPolicy ImagePolicy) (ImagePolicy, error) {
 	if value == "" {
 		return defaultPolicy, nil
@@ -398,11 +824,37 @@ func validateRun(name string, alias *Alias, baseDir string) error {
 		return err
 	}
 
+	if forwardErr := validateForward(name, alias.Run.Forward); forwardErr != nil {
+		return forwardErr
+	}
+
+	switch alias.Run.Display {
+	case "", DisplayX11, DisplayWayland, DisplayAuto:
+	default:
+		return fmt.Errorf("aliases.%s.run.display: must be x11|wayland|auto", name)
+	}
+
+	if urlsErr := validateURLs(name, alias.Run.URLs); urlsErr != nil {
+		return urlsErr
+	}
+
+	if mountCwd := alias.Run.MountCwd; mountCwd != nil && mountCwd.Enabled {
+		if mountCwd.Target == "" {
+			mountCwd.Target = DefaultMountCwdTarget
+		}
+		if !strings.HasPrefix(mountCwd.Target, "/") {
+			return fmt.Errorf("aliases.%s.run.mount_cwd.target: must be an absolute path", name)
+		}
+	}
+
 	alias.Run.Volumes = volumes
 	return nil
 }
 
 func validateRunIDs(name string, run RunSpec) error {
+	if run.HostUser && (run.User != "" || run.UID != 0 || run.GID != 0) {
+		return fmt.Errorf("aliases.%s.run.host_user: cannot be combined with user, uid or gid", name)
+	}
 	if run.UID == 0 && run.GID == 0 {
 		return nil
 	}
@@ -415,6 +867,42 @@ func validateRunIDs(name string, run RunSpec) error {
 	return nil
 }
 
+func validateURLs(name string, urls map[string]PortURLSpec) error {
+	for key, spec := range urls {
+		port, proto, _ := strings.Cut(key, "/")
+		if num, err := strconv.ParseUint(port, 10, 16); err != nil || num == 0 {
+			return fmt.Errorf("aliases.%s.run.urls.%s: key must be a container port", name, key)
+		}
+		if proto != "" && proto != "tcp" {
+			return fmt.Errorf("aliases.%s.run.urls.%s: only tcp ports have urls", name, key)
+		}
+		if spec.Scheme == "" {
+			spec.Scheme = "http"
+		}
+		if spec.Path != "" && !strings.HasPrefix(spec.Path, "/") {
+			return fmt.Errorf("aliases.%s.run.urls.%s.path: must start with /", name, key)
+		}
+		urls[key] = spec
+	}
+	return nil
+}
+
+func validateForward(name string, forward []ForwardKind) error {
+	seen := map[ForwardKind]bool{}
+	for i, kind := range forward {
+		switch kind {
+		case ForwardSSHAgent, ForwardGPGAgent, ForwardGitConfig:
+		default:
+			return fmt.Errorf("aliases.%s.run.forward[%d]: must be ssh-agent|gpg-agent|gitconfig", name, i)
+		}
+		if seen[kind] {
+			return fmt.Errorf("aliases.%s.run.forward[%d]: duplicate %q", name, i, kind)
+		}
+		seen[kind] = true
+	}
+	return nil
+}
+
 func validateMounts(name string, volumes []MountSpec, baseDir string) ([]MountSpec, error) {
 	validated := make([]MountSpec, len(volumes))
 	for i, v := range volumes {
@@ -428,6 +916,13 @@ func validateMounts(name string, volumes []MountSpec, baseDir string) ([]MountSp
 }
 
 func validateMount(name string, idx int, volume MountSpec, baseDir string) (MountSpec, error) {
+	if volume.short != "" {
+		parsed, err := parseShortMount(volume.short)
+		if err != nil {
+			return volume, fmt.Errorf("aliases.%s.run.volumes[%d]: invalid %q: %w", name, idx, volume.short, err)
+		}
+		volume = parsed
+	}
 	if volume.Type == "" || volume.Target == "" {
 		return volume, fmt.Errorf("aliases.%s.run.volumes[%d]: type and target are required", name, idx)
 	}
@@ -441,13 +936,65 @@ func validateMount(name string, idx int, volume MountSpec, baseDir string) (Moun
 		return volume, fmt.Errorf("aliases.%s.run.volumes[%d].source: required for %s", name, idx, volume.Type)
 	}
 
-	if volume.Type == "bind" && volume.Source != "" && !filepath.IsAbs(volume.Source) {
-		volume.Source = resolvePath(baseDir, volume.Source)
+	if volume.Type == "bind" {
+		source, err := expandHome(volume.Source)
+		if err != nil {
+			return volume, fmt.Errorf("aliases.%s.run.volumes[%d].source: %w", name, idx, err)
+		}
+		volume.Source = resolvePath(baseDir, source)
+	}
+
+	if volume.Create != nil {
+		if volume.Type != "bind" {
+			return volume, fmt.Errorf("aliases.%s.run.volumes[%d].create: only allowed for type bind", name, idx)
+		}
+		if volume.Bind != nil && volume.Bind.CreateHostPath {
+			return volume, fmt.Errorf(
+				"aliases.%s.run.volumes[%d].create: cannot be combined with bind.create_host_path", name, idx,
+			)
+		}
+	}
+
+	if err := validateMountOptions(volume); err != nil {
+		return volume, fmt.Errorf("aliases.%s.run.volumes[%d].%w", name, idx, err)
 	}
 
 	return volume, nil
 }
 
+func validateMountOptions(volume MountSpec) error {
+	options := []struct {
+		field string
+		set   bool
+	}{
+		{field: "bind", set: volume.Bind != nil},
+		{field: "volume", set: volume.Volume != nil},
+		{field: "tmpfs", set: volume.Tmpfs != nil},
+	}
+	for _, opt := range options {
+		if opt.set && opt.field != volume.Type {
+			return fmt.Errorf("%s: not allowed for type %s", opt.field, volume.Type)
+		}
+	}
+	if volume.Bind != nil && volume.Bind.Propagation != "" && !isPropagation(volume.Bind.Propagation) {
+		return errors.New("bind.propagation: must be rprivate|private|rshared|shared|rslave|slave")
+	}
+	if volume.Volume != nil && filepath.IsAbs(volume.Volume.Subpath) {
+		return errors.New("volume.subpath: must be relative to the volume root")
+	}
+	if volume.Tmpfs != nil && volume.Tmpfs.Size != "" {
+		if _, err := units.RAMInBytes(volume.Tmpfs.Size); err != nil {
+			return fmt.Errorf("tmpfs.size: must be a size such as 64m: %w", err)
+		}
+	}
+	if volume.Tmpfs != nil && volume.Tmpfs.Mode != "" {
+		if _, err := strconv.ParseUint(volume.Tmpfs.Mode, 8, 32); err != nil {
+			return fmt.Errorf("tmpfs.mode: must be an octal file mode: %w", err)
+		}
+	}
+	return nil
+}
+
 var ErrBadExpansion = errors.New("bad ${...} expansion syntax")
 
 func resolvePath(baseDir, p string) string {
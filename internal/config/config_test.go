@@ -1,11 +1,15 @@
 package config_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/config"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadFileResolvesPaths(t *testing.T) {
@@ -164,6 +168,19 @@ func TestValidateImagePolicyDefaults(t *testing.T) {
 	if cfg.Aliases["build"].Image.Build.Policy != config.ImagePolicyAlways {
 		t.Fatalf("expected build policy default to always")
 	}
+	if !cfg.Aliases["pull"].Image.Pull.PolicyDefaulted() || !cfg.Aliases["build"].Image.Build.PolicyDefaulted() {
+		t.Fatalf("expected unset policies to be reported as defaulted")
+	}
+
+	explicit := &config.Config{Aliases: map[string]config.Alias{
+		"pull": {Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04", Policy: config.ImagePolicyAlways}}},
+	}}
+	if err := explicit.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if explicit.Aliases["pull"].Image.Pull.PolicyDefaulted() {
+		t.Fatalf("expected an explicit always policy not to be reported as defaulted")
+	}
 }
 
 func TestValidateImagePolicyInvalid(t *testing.T) {
@@ -327,6 +344,31 @@ aliases:
 	}
 }
 
+func TestValidateGitContext(t *testing.T) {
+	valid := &config.Config{Aliases: map[string]config.Alias{
+		"demo": {Image: config.ImageSpec{Build: &config.BuildSpec{
+			Git: &config.GitContextSpec{URL: "https://github.com/org/repo.git", Ref: "v1", Subdir: "images/dev"},
+		}}},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]*config.BuildSpec{
+		"missing url":     {Git: &config.GitContextSpec{}},
+		"with cwd":        {Cwd: "/tmp", Git: &config.GitContextSpec{URL: "https://example.com/r.git"}},
+		"with remote":     {RemoteContext: "https://x", Git: &config.GitContextSpec{URL: "https://example.com/r.git"}},
+		"absolute dir":    {Git: &config.GitContextSpec{URL: "https://example.com/r.git", Subdir: "/etc"}},
+		"escaping subdir": {Git: &config.GitContextSpec{URL: "https://example.com/r.git", Subdir: "a/../../b"}},
+	}
+	for name, build := range cases {
+		cfg := &config.Config{Aliases: map[string]config.Alias{"demo": {Image: config.ImageSpec{Build: build}}}}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
 func TestLoadFilePullPlatformAuth(t *testing.T) {
 	dir := t.TempDir()
 	cfgPath := filepath.Join(dir, "config.yaml")
@@ -527,7 +569,8 @@ func assertBuildCacheAndTags(t *testing.T, build *config.BuildSpec) {
 	if !build.PullParent || !build.NoCache {
 		t.Fatalf("unexpected pull/no_cache: %v %v", build.PullParent, build.NoCache)
 	}
-	if len(build.CacheFrom) != 1 || build.CacheFrom[0] != "ghcr.io/org/app:cache" {
+	if len(build.CacheFrom) != 1 || build.CacheFrom[0].Type != config.CacheTypeRegistry ||
+		build.CacheFrom[0].Ref != "ghcr.io/org/app:cache" {
 		t.Fatalf("unexpected cache_from: %+v", build.CacheFrom)
 	}
 	if len(build.Tags) != 1 || build.Tags[0] != "demo:latest" {
@@ -604,3 +647,608 @@ func assertBuildNetworkAndPlatforms(t *testing.T, build *config.BuildSpec) {
 		t.Fatalf("unexpected platforms: %+v", build.Platforms)
 	}
 }
+
+func TestLoadFileHooks(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+aliases:
+  demo:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    pre_build: ["mkdir -p ./certs"]
+    post_build: ["echo built"]
+    pre_run: ["ssh-add -l"]
+    post_run: ["echo $CRADLE_CONTAINER_ID"]
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+
+	a := cfg.Aliases["demo"]
+	if len(a.PreBuild) != 1 || len(a.PostBuild) != 1 || len(a.PreRun) != 1 || len(a.PostRun) != 1 {
+		t.Fatalf("unexpected hooks: %+v", a)
+	}
+}
+
+func TestValidateHooksEmptyCommand(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image:  config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				PreRun: []string{"  "},
+			},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error for empty hook command")
+	}
+}
+
+func TestValidateHostUserConflicts(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run:   config.RunSpec{HostUser: true, User: "root"},
+			},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error when host_user is combined with user")
+	}
+
+	cfg.Aliases["demo"] = config.Alias{
+		Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+		Run:   config.RunSpec{HostUser: true},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+}
+
+func TestValidateForward(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run:   config.RunSpec{Forward: []config.ForwardKind{"bogus"}},
+			},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error for unknown forward")
+	}
+
+	cfg.Aliases["demo"] = config.Alias{
+		Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+		Run:   config.RunSpec{Forward: []config.ForwardKind{config.ForwardSSHAgent, config.ForwardSSHAgent}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error for duplicate forward")
+	}
+}
+
+func TestValidateDisplay(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run:   config.RunSpec{Display: "vnc"},
+			},
+		},
+	}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "aliases.demo.run.display") {
+		t.Fatalf("expected display error, got %v", err)
+	}
+
+	cfg.Aliases["demo"] = config.Alias{
+		Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+		Run:   config.RunSpec{Display: config.DisplayAuto},
+	}
+	if err = cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for auto display: %v", err)
+	}
+}
+
+func TestLoadFileMountCwd(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+aliases:
+  short:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      mount_cwd: true
+  long:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      mount_cwd:
+        target: /src
+        git_root: false
+  off:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      mount_cwd: false
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	short := cfg.Aliases["short"].Run.MountCwd
+	if short == nil || !short.Enabled || short.Target != config.DefaultMountCwdTarget {
+		t.Fatalf("unexpected short mount_cwd: %+v", short)
+	}
+	long := cfg.Aliases["long"].Run.MountCwd
+	if long == nil || !long.Enabled || long.Target != "/src" || long.GitRoot == nil || *long.GitRoot {
+		t.Fatalf("unexpected long mount_cwd: %+v", long)
+	}
+	if off := cfg.Aliases["off"].Run.MountCwd; off == nil || off.Enabled {
+		t.Fatalf("unexpected off mount_cwd: %+v", off)
+	}
+}
+
+func TestValidateMountCwdRelativeTarget(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run:   config.RunSpec{MountCwd: &config.MountCwdSpec{Enabled: true, Target: "src"}},
+			},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error for relative mount_cwd target")
+	}
+}
+
+func TestValidateURLs(t *testing.T) {
+	cases := []struct {
+		name    string
+		urls    map[string]config.PortURLSpec
+		wantErr bool
+	}{
+		{name: "defaults scheme", urls: map[string]config.PortURLSpec{"8443": {Path: "/"}}},
+		{name: "tcp suffix", urls: map[string]config.PortURLSpec{"3000/tcp": {Scheme: "https"}}},
+		{name: "not a port", urls: map[string]config.PortURLSpec{"web": {}}, wantErr: true},
+		{name: "udp", urls: map[string]config.PortURLSpec{"53/udp": {}}, wantErr: true},
+		{name: "relative path", urls: map[string]config.PortURLSpec{"80": {Path: "app"}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Aliases: map[string]config.Alias{
+					"demo": {
+						Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+						Run:   config.RunSpec{URLs: tc.urls},
+					},
+				},
+			}
+			err := cfg.Validate()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("wantErr=%v, got %v", tc.wantErr, err)
+			}
+			if err == nil {
+				for key, spec := range cfg.Aliases["demo"].Run.URLs {
+					if spec.Scheme == "" {
+						t.Fatalf("expected default scheme for %s", key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileDeclaredResources(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+networks:
+  devnet:
+    driver: bridge
+    labels:
+      team: dev
+  shared:
+    external: true
+volumes:
+  npm-cache: {}
+aliases:
+  web:
+    image:
+      pull:
+        ref: node:22
+    run:
+      network_mode: devnet
+      networks:
+        shared: {}
+      volumes:
+        - type: volume
+          source: npm-cache
+          target: /home/node/.npm
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	if cfg.Networks["devnet"].Driver != "bridge" || !cfg.Networks["shared"].External {
+		t.Fatalf("unexpected networks: %+v", cfg.Networks)
+	}
+	if _, ok := cfg.Volumes["npm-cache"]; !ok {
+		t.Fatalf("expected npm-cache volume to be declared")
+	}
+}
+
+func TestValidateUndeclaredResources(t *testing.T) {
+	image := config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}}
+	cases := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{
+			name: "network mode",
+			cfg: config.Config{Aliases: map[string]config.Alias{"demo": {
+				Image: image,
+				Run:   config.RunSpec{NetworkMode: "devnet"},
+			}}},
+			want: "run.network_mode",
+		},
+		{
+			name: "networks",
+			cfg: config.Config{Aliases: map[string]config.Alias{"demo": {
+				Image: image,
+				Run:   config.RunSpec{Networks: map[string]config.NetworkSpec{"devnet": {}}},
+			}}},
+			want: "run.networks.devnet",
+		},
+		{
+			name: "volume",
+			cfg: config.Config{Aliases: map[string]config.Alias{"demo": {
+				Image: image,
+				Run:   config.RunSpec{Volumes: []config.MountSpec{{Type: "volume", Source: "cache", Target: "/cache"}}},
+			}}},
+			want: "run.volumes[0].source",
+		},
+		{
+			name: "external with driver",
+			cfg: config.Config{
+				Networks: map[string]config.DeclaredNetworkSpec{"shared": {External: true, Driver: "bridge"}},
+			},
+			want: "networks.shared",
+		},
+		{
+			name: "builtin declared",
+			cfg:  config.Config{Networks: map[string]config.DeclaredNetworkSpec{"host": {}}},
+			want: "networks.host",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error mentioning %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateBuiltinNetworksNeedNoDeclaration(t *testing.T) {
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run: config.RunSpec{
+					NetworkMode: "host",
+					Networks:    map[string]config.NetworkSpec{"default": {}},
+				},
+			},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected built-in networks to validate, got %v", err)
+	}
+}
+
+func TestLoadFileMountOptions(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+volumes:
+  data: {}
+aliases:
+  demo:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      volumes:
+        - type: bind
+          source: ./src
+          target: /src
+          bind:
+            propagation: rslave
+            create_host_path: true
+        - type: volume
+          source: data
+          target: /data
+          volume:
+            nocopy: true
+            subpath: app
+        - type: tmpfs
+          target: /scratch
+          tmpfs:
+            size: 64m
+            mode: "1777"
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	volumes := cfg.Aliases["demo"].Run.Volumes
+	if volumes[0].Bind == nil || volumes[0].Bind.Propagation != "rslave" || !volumes[0].Bind.CreateHostPath {
+		t.Fatalf("unexpected bind options: %+v", volumes[0].Bind)
+	}
+	if volumes[1].Volume == nil || !volumes[1].Volume.NoCopy || volumes[1].Volume.Subpath != "app" {
+		t.Fatalf("unexpected volume options: %+v", volumes[1].Volume)
+	}
+	if volumes[2].Tmpfs == nil || volumes[2].Tmpfs.Size != "64m" || volumes[2].Tmpfs.Mode != "1777" {
+		t.Fatalf("unexpected tmpfs options: %+v", volumes[2].Tmpfs)
+	}
+}
+
+func TestValidateMountOptions(t *testing.T) {
+	create := true
+	cases := []struct {
+		name  string
+		mount config.MountSpec
+		want  string
+	}{
+		{
+			name:  "options for other type",
+			mount: config.MountSpec{Type: "tmpfs", Target: "/tmp", Bind: &config.BindOptionsSpec{}},
+			want:  "volumes[0].bind: not allowed for type tmpfs",
+		},
+		{
+			name: "propagation",
+			mount: config.MountSpec{
+				Type:   "bind",
+				Source: "/src",
+				Target: "/src",
+				Bind:   &config.BindOptionsSpec{Propagation: "everywhere"},
+			},
+			want: "volumes[0].bind.propagation",
+		},
+		{
+			name:  "tmpfs mode",
+			mount: config.MountSpec{Type: "tmpfs", Target: "/tmp", Tmpfs: &config.TmpfsOptionsSpec{Mode: "rwx"}},
+			want:  "volumes[0].tmpfs.mode",
+		},
+		{
+			name: "create and create_host_path",
+			mount: config.MountSpec{
+				Type:   "bind",
+				Source: "/src",
+				Target: "/src",
+				Create: &create,
+				Bind:   &config.BindOptionsSpec{CreateHostPath: true},
+			},
+			want: "volumes[0].create: cannot be combined with bind.create_host_path",
+		},
+		{
+			name:  "tmpfs size",
+			mount: config.MountSpec{Type: "tmpfs", Target: "/tmp", Tmpfs: &config.TmpfsOptionsSpec{Size: "lots"}},
+			want:  "volumes[0].tmpfs.size",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Aliases: map[string]config.Alias{
+					"demo": {
+						Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+						Run:   config.RunSpec{Volumes: []config.MountSpec{tc.mount}},
+					},
+				},
+			}
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error mentioning %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadFileShortVolumes(t *testing.T) {
+	dir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+volumes:
+  named-vol: {}
+aliases:
+  demo:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      volumes:
+        - ./src:/work
+        - ~/cache:/cache:ro
+        - named-vol:/data:nocopy
+        - type: tmpfs
+          target: /tmp
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	volumes := cfg.Aliases["demo"].Run.Volumes
+	if len(volumes) != 4 {
+		t.Fatalf("expected 4 volumes, got %+v", volumes)
+	}
+	if volumes[0].Type != "bind" || volumes[0].Source != filepath.Join(dir, "src") || volumes[0].Target != "/work" {
+		t.Fatalf("unexpected relative bind: %+v", volumes[0])
+	}
+	if volumes[1].Type != "bind" || volumes[1].Source != filepath.Join(home, "cache") || !volumes[1].ReadOnly {
+		t.Fatalf("unexpected home bind: %+v", volumes[1])
+	}
+	if volumes[2].Type != "volume" || volumes[2].Source != "named-vol" || volumes[2].Volume == nil ||
+		!volumes[2].Volume.NoCopy {
+		t.Fatalf("unexpected named volume: %+v", volumes[2])
+	}
+	if volumes[3].Type != "tmpfs" {
+		t.Fatalf("unexpected tmpfs: %+v", volumes[3])
+	}
+}
+
+func TestMountSpecUnmarshalJSONShort(t *testing.T) {
+	var run config.RunSpec
+	if err := json.Unmarshal([]byte(`{"volumes": ["/src:/work:ro,rslave"]}`), &run); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"demo": {Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}}, Run: run},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	m := cfg.Aliases["demo"].Run.Volumes[0]
+	if m.Type != "bind" || m.Source != "/src" || !m.ReadOnly || m.Bind == nil || m.Bind.Propagation != "rslave" {
+		t.Fatalf("unexpected mount: %+v", m)
+	}
+}
+
+func TestValidateShortVolumeInvalid(t *testing.T) {
+	for _, raw := range []string{"/only-source", "/src:/dst:bogus", "vol:/data:rshared", "a:b:c:d"} {
+		var run config.RunSpec
+		if err := json.Unmarshal([]byte(`{"volumes": ["`+raw+`"]}`), &run); err != nil {
+			t.Fatalf("unmarshal %q: %v", raw, err)
+		}
+		cfg := &config.Config{
+			Volumes: map[string]config.DeclaredVolumeSpec{"vol": {}},
+			Aliases: map[string]config.Alias{
+				"demo": {Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}}, Run: run},
+			},
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "run.volumes[0]") {
+			t.Fatalf("expected error for %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestValidateBindCreate(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	newCfg := func(create bool, mountType string) *config.Config {
+		source := missing
+		if mountType == "volume" {
+			source = "vol"
+		}
+		return &config.Config{
+			Volumes: map[string]config.DeclaredVolumeSpec{"vol": {}},
+			Aliases: map[string]config.Alias{
+				"demo": {
+					Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+					Run: config.RunSpec{Volumes: []config.MountSpec{
+						{Type: mountType, Source: source, Target: "/data", Create: &create},
+					}},
+				},
+			},
+		}
+	}
+	if err := newCfg(false, "bind").Validate(); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing source error, got %v", err)
+	}
+	if err := newCfg(true, "bind").Validate(); err != nil {
+		t.Fatalf("expected create: true to allow a missing source, got %v", err)
+	}
+	if err := newCfg(true, "volume").Validate(); err == nil || !strings.Contains(err.Error(), "create") {
+		t.Fatalf("expected create to be rejected for volumes, got %v", err)
+	}
+}
+
+func TestMountCwdMarshalYAML(t *testing.T) {
+	gitRoot := false
+	cases := []struct {
+		name  string
+		mount config.MountCwdSpec
+	}{
+		{name: "disabled", mount: config.MountCwdSpec{Target: "/src"}},
+		{name: "enabled", mount: config.MountCwdSpec{Enabled: true, Target: "/src", GitRoot: &gitRoot}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := yaml.Marshal(&tc.mount)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var got config.MountCwdSpec
+			if err = yaml.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got.Enabled != tc.mount.Enabled {
+				t.Fatalf("expected enabled %v after round trip, got %+v from %q", tc.mount.Enabled, got, data)
+			}
+		})
+	}
+}
+
+func TestParsePortableSkipsBindSources(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	data := []byte(`
+version: 1
+aliases:
+  demo:
+    image:
+      pull:
+        ref: ubuntu:24.04
+    run:
+      volumes:
+        - type: bind
+          source: ` + missing + `
+          target: /data
+          create: false
+`)
+	if _, err := config.Parse(data, ""); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing source error, got %v", err)
+	}
+	if _, err := config.ParsePortable(data); err != nil {
+		t.Fatalf("ParsePortable error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Members returns the aliases started by `cradle up name`: the members of group name,
+// or the alias itself.
+func (c *Config) Members(name string) ([]string, error) {
+	if members, ok := c.Groups[name]; ok {
+		return members, nil
+	}
+	if _, ok := c.Aliases[name]; ok {
+		return []string{name}, nil
+	}
+	return nil, fmt.Errorf("unknown alias or group %q", name)
+}
+
+// StartOrder returns names and their transitive depends_on in start order: every alias
+// appears after its dependencies, otherwise in the order it was first reached.
+func (c *Config) StartOrder(names []string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var order []string
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			cycle := slices.Concat(path[slices.Index(path, name):], []string{name})
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+		alias, ok := c.Aliases[name]
+		if !ok {
+			return fmt.Errorf("unknown alias %q", name)
+		}
+		state[name] = visiting
+		for _, dep := range alias.DependsOn {
+			if err := visit(dep, slices.Concat(path, []string{name})); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (c *Config) validateDependencies() error {
+	for _, name := range slices.Sorted(maps.Keys(c.Aliases)) {
+		for i, dep := range c.Aliases[name].DependsOn {
+			if dep == name {
+				return fmt.Errorf("aliases.%s.depends_on[%d]: alias cannot depend on itself", name, i)
+			}
+			if _, ok := c.Aliases[dep]; !ok {
+				return fmt.Errorf("aliases.%s.depends_on[%d]: unknown alias %q", name, i, dep)
+			}
+		}
+		if _, err := c.StartOrder([]string{name}); err != nil {
+			return fmt.Errorf("aliases.%s.depends_on: %w", name, err)
+		}
+	}
+	for _, group := range slices.Sorted(maps.Keys(c.Groups)) {
+		if _, ok := c.Aliases[group]; ok {
+			return fmt.Errorf("groups.%s: name is already used by an alias", group)
+		}
+		members := c.Groups[group]
+		if len(members) == 0 {
+			return fmt.Errorf("groups.%s: must list at least one alias", group)
+		}
+		for i, member := range members {
+			if _, ok := c.Aliases[member]; !ok {
+				return fmt.Errorf("groups.%s[%d]: unknown alias %q", group, i, member)
+			}
+		}
+	}
+	return nil
+}
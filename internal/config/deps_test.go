@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+func depsConfig(deps map[string][]string) *config.Config {
+	cfg := &config.Config{Aliases: map[string]config.Alias{}}
+	for name, dependsOn := range deps {
+		cfg.Aliases[name] = config.Alias{
+			Image:     config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+			DependsOn: dependsOn,
+		}
+	}
+	return cfg
+}
+
+func TestStartOrder(t *testing.T) {
+	cfg := depsConfig(map[string][]string{
+		"backend": {"db", "cache"},
+		"cache":   {"db"},
+		"db":      nil,
+	})
+	order, err := cfg.StartOrder([]string{"backend"})
+	if err != nil {
+		t.Fatalf("StartOrder error: %v", err)
+	}
+	if want := []string{"db", "cache", "backend"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+}
+
+func TestValidateDependencyCycle(t *testing.T) {
+	cfg := depsConfig(map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	})
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: a -> b -> c -> a") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestValidateDependsOnUnknown(t *testing.T) {
+	cfg := depsConfig(map[string][]string{"backend": {"db"}})
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "aliases.backend.depends_on[0]") {
+		t.Fatalf("expected unknown dependency error, got %v", err)
+	}
+
+	cfg = depsConfig(map[string][]string{"backend": {"backend"}})
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "itself") {
+		t.Fatalf("expected self dependency error, got %v", err)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	cfg := depsConfig(map[string][]string{"backend": {"db"}, "db": nil})
+	cfg.Groups = map[string][]string{"stack": {"backend"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	members, err := cfg.Members("stack")
+	if err != nil || !reflect.DeepEqual(members, []string{"backend"}) {
+		t.Fatalf("unexpected group members %v: %v", members, err)
+	}
+	members, err = cfg.Members("db")
+	if err != nil || !reflect.DeepEqual(members, []string{"db"}) {
+		t.Fatalf("expected alias to resolve to itself, got %v: %v", members, err)
+	}
+	if _, err = cfg.Members("nope"); err == nil {
+		t.Fatalf("expected error for unknown name")
+	}
+
+	cfg.Groups = map[string][]string{"db": {"backend"}}
+	if err = cfg.Validate(); err == nil || !strings.Contains(err.Error(), "groups.db") {
+		t.Fatalf("expected alias name clash error, got %v", err)
+	}
+	cfg.Groups = map[string][]string{"stack": {"web"}}
+	if err = cfg.Validate(); err == nil || !strings.Contains(err.Error(), "groups.stack[0]") {
+		t.Fatalf("expected unknown member error, got %v", err)
+	}
+}
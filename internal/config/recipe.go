@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageManager selects the install commands a recipe generates.
+type PackageManager string
+
+const (
+	PackageManagerApt    PackageManager = "apt"
+	PackageManagerDnf    PackageManager = "dnf"
+	PackageManagerPacman PackageManager = "pacman"
+	PackageManagerZypper PackageManager = "zypper"
+)
+
+// RecipeSpec describes an image that cradle turns into a Dockerfile: a base image,
+// packages installed with its package manager, and an optional user.
+type RecipeSpec struct {
+	Base string `json:"base" yaml:"base"` // e.g. ubuntu:24.04
+	// PackageManager defaults to the one detected from the base image name.
+	PackageManager PackageManager `json:"package_manager,omitempty" yaml:"package_manager,omitempty"`
+	Packages       []string       `json:"packages,omitempty"        yaml:"packages,omitempty"`
+	// PackagesFile lists more packages, one or more per line; # starts a comment.
+	PackagesFile string `json:"packages_file,omitempty" yaml:"packages_file,omitempty"`
+	// Setup runs shell commands as root before packages are installed.
+	Setup []string        `json:"setup,omitempty" yaml:"setup,omitempty"`
+	User  *RecipeUserSpec `json:"user,omitempty"  yaml:"user,omitempty"`
+}
+
+// RecipeUserSpec creates a login user that the image runs as.
+type RecipeUserSpec struct {
+	Name string `json:"name"          yaml:"name"`
+	UID  *int   `json:"uid,omitempty" yaml:"uid,omitempty"` // default: 1000
+	// Sudo grants passwordless sudo (default: true).
+	Sudo  *bool  `json:"sudo,omitempty"  yaml:"sudo,omitempty"`
+	Shell string `json:"shell,omitempty" yaml:"shell,omitempty"` // default: /bin/bash
+}
+
+// DefaultRecipeUID is the user ID a recipe user gets when uid is not set.
+const DefaultRecipeUID = 1000
+
+// packageManagerImages maps base image repository names to their package manager.
+var packageManagerImages = map[string]PackageManager{
+	"debian":       PackageManagerApt,
+	"ubuntu":       PackageManagerApt,
+	"kalilinux":    PackageManagerApt,
+	"fedora":       PackageManagerDnf,
+	"almalinux":    PackageManagerDnf,
+	"rockylinux":   PackageManagerDnf,
+	"centos":       PackageManagerDnf,
+	"ubi9":         PackageManagerDnf,
+	"amazonlinux":  PackageManagerDnf,
+	"oraclelinux":  PackageManagerDnf,
+	"archlinux":    PackageManagerPacman,
+	"manjarolinux": PackageManagerPacman,
+	"leap":         PackageManagerZypper,
+	"tumbleweed":   PackageManagerZypper,
+	"sle15":        PackageManagerZypper,
+	"bci-base":     PackageManagerZypper,
+}
+
+// DetectPackageManager guesses the package manager from a base image reference,
+// returning "" when the image is not recognized.
+func DetectPackageManager(base string) PackageManager {
+	name := base
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		name = name[:colon]
+	}
+	return packageManagerImages[name]
+}
+
+// maxRecipeUserName is the longest user name useradd accepts on common distros.
+const maxRecipeUserName = 32
+
+// isRecipeUserName reports whether name is a portable user name. The name ends up
+// in the generated Dockerfile and shell commands, so nothing else is allowed.
+func isRecipeUserName(name string) bool {
+	if name == "" || len(name) > maxRecipeUserName || name[0] == '-' || name[0] == '.' ||
+		(name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func validateRecipe(name string, recipe *RecipeSpec, baseDir string) error {
+	if recipe.Base == "" {
+		return fmt.Errorf("aliases.%s.image.build.recipe.base: required", name)
+	}
+	switch recipe.PackageManager {
+	case PackageManagerApt, PackageManagerDnf, PackageManagerPacman, PackageManagerZypper:
+	case "":
+		recipe.PackageManager = DetectPackageManager(recipe.Base)
+		if recipe.PackageManager == "" {
+			return fmt.Errorf(
+				"aliases.%s.image.build.recipe.package_manager: cannot detect from %q; set apt|dnf|pacman|zypper",
+				name, recipe.Base,
+			)
+		}
+	default:
+		return fmt.Errorf("aliases.%s.image.build.recipe.package_manager: must be apt|dnf|pacman|zypper", name)
+	}
+	recipe.PackagesFile = resolvePath(baseDir, recipe.PackagesFile)
+	for i, command := range recipe.Setup {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("aliases.%s.image.build.recipe.setup[%d]: command is required", name, i)
+		}
+	}
+	if user := recipe.User; user != nil {
+		if user.Name == "" {
+			return fmt.Errorf("aliases.%s.image.build.recipe.user.name: required", name)
+		}
+		if !isRecipeUserName(user.Name) {
+			return fmt.Errorf(
+				"aliases.%s.image.build.recipe.user.name: %q must start with a letter or _ and contain only "+
+					"letters, digits, _, . and - (at most %d characters)",
+				name, user.Name, maxRecipeUserName,
+			)
+		}
+		if user.UID == nil {
+			uid := DefaultRecipeUID
+			user.UID = &uid
+		}
+		if *user.UID < 0 {
+			return fmt.Errorf("aliases.%s.image.build.recipe.user.uid: must not be negative", name)
+		}
+		if user.Shell == "" {
+			user.Shell = "/bin/bash"
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	cases := map[string]config.PackageManager{
+		"ubuntu:24.04":                         config.PackageManagerApt,
+		"debian":                               config.PackageManagerApt,
+		"almalinux:9":                          config.PackageManagerDnf,
+		"rockylinux:9":                         config.PackageManagerDnf,
+		"registry.fedoraproject.org/fedora:44": config.PackageManagerDnf,
+		"archlinux:base-devel":                 config.PackageManagerPacman,
+		"opensuse/leap:16.0":                   config.PackageManagerZypper,
+		"alpine:3.20":                          "",
+	}
+	for base, want := range cases {
+		if got := config.DetectPackageManager(base); got != want {
+			t.Fatalf("DetectPackageManager(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestLoadFileRecipe(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := `
+version: 1
+aliases:
+  dev:
+    image:
+      build:
+        recipe:
+          base: ubuntu:24.04
+          packages: [git]
+          packages_file: ./packages.txt
+          user:
+            name: dev
+  inline:
+    image:
+      build:
+        dockerfile_inline: |
+          FROM alpine:3.20
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := config.LoadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+
+	recipe := cfg.Aliases["dev"].Image.Build.Recipe
+	if recipe.PackageManager != config.PackageManagerApt {
+		t.Fatalf("expected detected apt, got %q", recipe.PackageManager)
+	}
+	if recipe.PackagesFile != filepath.Join(dir, "packages.txt") {
+		t.Fatalf("packages_file not resolved: %q", recipe.PackagesFile)
+	}
+	if recipe.User.UID == nil || *recipe.User.UID != config.DefaultRecipeUID || recipe.User.Shell != "/bin/bash" {
+		t.Fatalf("unexpected user defaults: %+v", recipe.User)
+	}
+	if cfg.Aliases["dev"].Image.Build.Dockerfile != "" || cfg.Aliases["inline"].Image.Build.Dockerfile != "" {
+		t.Fatalf("expected no dockerfile default for generated builds")
+	}
+}
+
+func TestValidateRecipeErrors(t *testing.T) {
+	cases := map[string]*config.BuildSpec{
+		"both sources":   {DockerfileInline: "FROM alpine", Recipe: &config.RecipeSpec{Base: "ubuntu"}},
+		"remote context": {DockerfileInline: "FROM alpine", RemoteContext: "https://example.com/repo.git"},
+		"dockerfile":     {Recipe: &config.RecipeSpec{Base: "ubuntu"}, Dockerfile: "Dockerfile.dev"},
+		"missing base":   {Recipe: &config.RecipeSpec{}},
+		"unknown base":   {Recipe: &config.RecipeSpec{Base: "alpine:3.20"}},
+		"bad manager":    {Recipe: &config.RecipeSpec{Base: "ubuntu", PackageManager: "apk"}},
+		"empty setup":    {Recipe: &config.RecipeSpec{Base: "ubuntu", Setup: []string{" "}}},
+		"user name":      {Recipe: &config.RecipeSpec{Base: "ubuntu", User: &config.RecipeUserSpec{}}},
+		"unsafe user name": {Recipe: &config.RecipeSpec{
+			Base: "ubuntu", User: &config.RecipeUserSpec{Name: "dev\nRUN rm -rf /"},
+		}},
+		"user name with space": {Recipe: &config.RecipeSpec{Base: "ubuntu", User: &config.RecipeUserSpec{Name: "a b"}}},
+	}
+	for name, build := range cases {
+		cfg := &config.Config{Aliases: map[string]config.Alias{
+			"demo": {Image: config.ImageSpec{Build: build}},
+		}}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
@@ -17,30 +17,43 @@ import (
 )
 
 type Handler struct {
-	mu     sync.Mutex
+	mu     *sync.Mutex
 	out    io.Writer
-	level  slog.Level
+	opts   *Options
 	attrs  []slog.Attr
 	groups []string
 	color  bool
 }
 
 func NewHandler(out io.Writer, level slog.Level) *Handler {
+	opts := &Options{}
+	opts.Level.Set(level)
+	return NewHandlerWithOptions(out, opts)
+}
+
+// NewHandlerWithOptions returns a handler that reads its level and format from opts
+// on every record.
+func NewHandlerWithOptions(out io.Writer, opts *Options) *Handler {
 	return &Handler{
+		mu:    &sync.Mutex{},
 		out:   out,
-		level: level,
+		opts:  opts,
 		color: IsTerminal(out),
 	}
 }
 
 func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.opts.Level.Level()
 }
 
-func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.opts.Format == FormatJSON {
+		return h.handleJSON(ctx, r)
+	}
+
 	var b strings.Builder
 	emoji, label, color := LevelStyle(r.Level)
 	if h.color {
@@ -68,10 +81,23 @@ func (h *Handler) Handle(_ context.Context, r slog.Record) error {
 	return err
 }
 
+// handleJSON writes r as one JSON object with the handler's groups and attrs.
+func (h *Handler) handleJSON(ctx context.Context, r slog.Record) error {
+	var jh slog.Handler = slog.NewJSONHandler(h.out, &slog.HandlerOptions{Level: slog.LevelDebug})
+	for _, group := range h.groups {
+		jh = jh.WithGroup(group)
+	}
+	if len(h.attrs) > 0 {
+		jh = jh.WithAttrs(h.attrs)
+	}
+	return jh.Handle(ctx, r)
+}
+
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &Handler{
+		mu:     h.mu,
 		out:    h.out,
-		level:  h.level,
+		opts:   h.opts,
 		color:  h.color,
 		attrs:  append(append([]slog.Attr{}, h.attrs...), attrs...),
 		groups: append([]string{}, h.groups...),
@@ -83,8 +109,9 @@ func (h *Handler) WithGroup(name string) slog.Handler {
 		return h
 	}
 	return &Handler{
+		mu:     h.mu,
 		out:    h.out,
-		level:  h.level,
+		opts:   h.opts,
 		color:  h.color,
 		attrs:  append([]slog.Attr{}, h.attrs...),
 		groups: append(append([]string{}, h.groups...), name),
@@ -1,10 +1,62 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 )
 
+// Format selects how a Handler writes records.
+type Format string
+
+const (
+	// FormatText writes one emoji-prefixed line per record.
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record, for tooling.
+	FormatJSON Format = "json"
+)
+
+// Options hold the level and format shared by the handlers of one process. They are
+// read on every record, so flags parsed after the loggers were created still apply.
+type Options struct {
+	Level  slog.LevelVar
+	Format Format
+}
+
 func New(out io.Writer) *slog.Logger {
 	return slog.New(NewHandler(out, slog.LevelInfo))
 }
+
+// NewWithOptions returns a logger whose level and format follow opts.
+func NewWithOptions(out io.Writer, opts *Options) *slog.Logger {
+	return slog.New(NewHandlerWithOptions(out, opts))
+}
+
+// ParseLevel parses debug, info, warn or error, in any case.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q (want debug|info|warn|error)", s)
+	}
+}
+
+// ParseFormat parses text or json.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("invalid log format %q (want text|json)", s)
+	}
+}
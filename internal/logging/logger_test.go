@@ -2,6 +2,8 @@ package logging_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"log/slog"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/logging"
@@ -21,3 +23,52 @@ func TestNewLoggerFiltersByLevel(t *testing.T) {
 		t.Fatalf("expected info to be written")
 	}
 }
+
+func TestNewWithOptionsFollowsChanges(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &logging.Options{}
+	logger := logging.NewWithOptions(&buf, opts).With("alias", "dev")
+
+	logger.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug to be filtered at the default level")
+	}
+
+	opts.Level.Set(slog.LevelDebug)
+	opts.Format = logging.FormatJSON
+	logger.Debug("shown", "exists", true)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "shown" || record["alias"] != "dev" || record["exists"] != true {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := logging.ParseLevel(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v", input, got, err)
+		}
+	}
+	if _, err := logging.ParseLevel("loud"); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if got, err := logging.ParseFormat("JSON"); err != nil || got != logging.FormatJSON {
+		t.Fatalf("ParseFormat(JSON) = %q, %v", got, err)
+	}
+	if _, err := logging.ParseFormat("yaml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
@@ -1,10 +1,14 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"net"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -20,9 +24,13 @@ const (
 	colImgStatus = 2
 	colCtrStatus = 4
 
+	colUpdateStatus = 4
+
 	zebraMod = 2
 
 	statusColWidth = 12
+
+	shortDigestLength = len("sha256:") + 12
 )
 
 // Renderer prints user-facing output for cradle commands.
@@ -57,16 +65,185 @@ func (r *Renderer) ListStatuses(items []service.AliasStatus) {
 	_, _ = fmt.Fprint(r.out, renderAliasStatusTable(items, w))
 }
 
+// Outdated renders a table of aliases with registry updates, or every checked alias
+// when all is set. Failed registry checks are logged as warnings.
+func (r *Renderer) Outdated(items []service.ImageUpdate, all bool) {
+	rows := make([]service.ImageUpdate, 0, len(items))
+	for _, item := range items {
+		if item.Error != "" {
+			r.log.Warn("registry check failed", "alias", item.Alias, "ref", item.Ref, "error", item.Error)
+		}
+		if all || item.Status == service.UpdateAvailable {
+			rows = append(rows, item)
+		}
+	}
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(r.out, "All pull images are up to date.")
+		return
+	}
+	_, _ = fmt.Fprint(r.out, renderOutdatedTable(rows, terminalWidth(r.out)))
+}
+
+// OutdatedJSON prints image update results as a JSON array.
+func (r *Renderer) OutdatedJSON(items []service.ImageUpdate) error {
+	if items == nil {
+		items = []service.ImageUpdate{}
+	}
+	return r.writeJSON(items)
+}
+
 // RunStart emits a log line indicating a container was started.
 func (r *Renderer) RunStart(id string) {
 	r.log.Info("container started", "id", id)
 }
 
+// RunPorts emits one log line per published port so users know which URL to open.
+func (r *Renderer) RunPorts(ports []service.PublishedPort) {
+	for _, port := range ports {
+		attrs := []any{
+			"container_port", port.ContainerPort + "/" + port.Protocol,
+			"host", net.JoinHostPort(port.HostIP, port.HostPort),
+		}
+		if port.URL != "" {
+			attrs = append(attrs, "url", port.URL)
+		}
+		r.log.Info("port published", attrs...)
+	}
+}
+
+// RunJSON prints the run result as a single JSON document.
+func (r *Renderer) RunJSON(result *service.RunResult) error {
+	if result.Ports == nil {
+		result.Ports = []service.PublishedPort{}
+	}
+	return r.writeJSON(result)
+}
+
+// PortList prints one line per published port: container port, host binding and URL.
+func (r *Renderer) PortList(ports []service.PublishedPort) {
+	if len(ports) == 0 {
+		_, _ = fmt.Fprintln(r.out, "No published ports.")
+		return
+	}
+	for _, port := range ports {
+		line := fmt.Sprintf(
+			"%s/%s -> %s",
+			port.ContainerPort,
+			port.Protocol,
+			net.JoinHostPort(port.HostIP, port.HostPort),
+		)
+		if port.URL != "" {
+			line += "  " + port.URL
+		}
+		_, _ = fmt.Fprintln(r.out, line)
+	}
+}
+
+// PortsJSON prints published ports as a JSON array.
+func (r *Renderer) PortsJSON(ports []service.PublishedPort) error {
+	if ports == nil {
+		ports = []service.PublishedPort{}
+	}
+	return r.writeJSON(ports)
+}
+
+// URL prints a bare URL.
+func (r *Renderer) URL(url string) {
+	_, _ = fmt.Fprintln(r.out, url)
+}
+
+func (r *Renderer) writeJSON(v any) error {
+	enc := json.NewEncoder(r.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// BundleSaved emits a log line for each image written to a bundle.
+func (r *Renderer) BundleSaved(path string, manifest *service.BundleManifest) {
+	for _, img := range manifest.Images {
+		r.log.Info("image saved", "alias", img.Alias, "ref", img.Ref, "id", img.ImageID)
+	}
+	r.log.Info("bundle written", "path", path)
+}
+
+// BundleLoaded emits a log line for each image tagged from a bundle.
+func (r *Renderer) BundleLoaded(manifest *service.BundleManifest) {
+	for _, img := range manifest.Images {
+		r.log.Info("image loaded", "alias", img.Alias, "tag", "cradle/"+img.Alias+":latest", "id", img.ImageID)
+	}
+}
+
+// LockWritten emits a log line per pinned image. When only is set, just those aliases
+// are reported.
+func (r *Renderer) LockWritten(path string, lock *service.Lock, only []string) {
+	for _, alias := range slices.Sorted(maps.Keys(lock.Aliases)) {
+		if len(only) > 0 && !slices.Contains(only, alias) {
+			continue
+		}
+		entry := lock.Aliases[alias]
+		if entry.Digest != "" {
+			r.log.Info("image locked", "alias", alias, "ref", entry.Ref, "digest", entry.Digest)
+		}
+		for _, base := range entry.Bases {
+			r.log.Info("base image locked", "alias", alias, "ref", base.Ref, "digest", base.Digest)
+		}
+	}
+	r.log.Info("lock written", "path", path)
+}
+
+// SnapshotCreated emits a log line with the reference of a committed snapshot.
+func (r *Renderer) SnapshotCreated(ref string) {
+	r.log.Info("snapshot created", "ref", ref)
+}
+
 // RunStop emits a log line indicating a container was stopped.
 func (r *Renderer) RunStop(id string) {
 	r.log.Info("container stopped", "id", id)
 }
 
+// RunRemove emits a log line indicating a container was removed.
+func (r *Renderer) RunRemove(id string) {
+	r.log.Info("container removed", "id", id)
+}
+
+// Doctor prints one line per check with its status, followed by the suggested fix
+// for checks that did not pass, and a summary line.
+func (r *Renderer) Doctor(checks []service.Check) {
+	counts := map[service.CheckStatus]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+		label := CheckStatusLabel(check.Status)
+		_, _ = fmt.Fprintf(r.out, "%s %-4s %s: %s\n", label, check.Status, check.Name, check.Detail)
+		if check.Fix != "" && check.Status != service.CheckPass {
+			_, _ = fmt.Fprintf(r.out, "          fix: %s\n", check.Fix)
+		}
+	}
+	_, _ = fmt.Fprintf(r.out, "\n%d passed, %d warnings, %d failed\n",
+		counts[service.CheckPass], counts[service.CheckWarn], counts[service.CheckFail])
+}
+
+// DoctorJSON prints the doctor checks as a JSON array.
+func (r *Renderer) DoctorJSON(checks []service.Check) error {
+	if checks == nil {
+		checks = []service.Check{}
+	}
+	return r.writeJSON(checks)
+}
+
+// CheckStatusLabel returns the emoji for a doctor check status.
+func CheckStatusLabel(status service.CheckStatus) string {
+	switch status {
+	case service.CheckPass:
+		return "✅"
+	case service.CheckWarn:
+		return "⚠️"
+	case service.CheckFail:
+		return "❌"
+	default:
+		return "❔"
+	}
+}
+
 // ImageStatusLabel returns an emoji label indicating whether an image exists locally.
 func ImageStatusLabel(present bool) string {
 	if present {
@@ -83,6 +260,43 @@ func ImageStatusText(present bool) string {
 	return "missing"
 }
 
+// imageStatus formats the image status cell, flagging images loaded from a bundle.
+func imageStatus(item service.AliasStatus) string {
+	switch item.Bundle {
+	case service.BundleMatch:
+		return "📦 bundled"
+	case service.BundleMismatch:
+		return "⚠️ mismatch"
+	case service.BundleNone:
+		return fmt.Sprintf("%s %s", ImageStatusLabel(item.ImagePresent), ImageStatusText(item.ImagePresent))
+	}
+	return ""
+}
+
+// UpdateStatusLabel returns an emoji label for an image update status.
+func UpdateStatusLabel(status service.UpdateStatus) string {
+	switch status {
+	case service.UpdateCurrent:
+		return "✅"
+	case service.UpdateAvailable:
+		return "⬆️"
+	case service.UpdateMissing:
+		return "❌"
+	case service.UpdateLocal:
+		return "🏠"
+	case service.UpdateUnknown:
+		return "🤷"
+	}
+	return "🤷"
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > shortDigestLength {
+		return digest[:shortDigestLength]
+	}
+	return digest
+}
+
 // ContainerStatusLabel returns an emoji label describing the container state.
 func ContainerStatusLabel(item service.AliasStatus) string {
 	if !item.ContainerPresent {
@@ -141,24 +355,51 @@ func terminalWidth(w io.Writer) int {
 	return width
 }
 
-func isStatusCol(col int) bool {
-	return col == colImgStatus || col == colCtrStatus
-}
-
 // renderAliasStatusTable renders the alias status table.
 // If width > 0, the table is constrained to that width and wrapping is enabled.
 func renderAliasStatusTable(items []service.AliasStatus, width int) string {
 	rows := make([][]string, 0, len(items))
 	for _, item := range items {
+		name := item.Name
+		if item.Instance != "" {
+			name = "  @" + item.Instance
+		}
+		ctrStatus := fmt.Sprintf("%s %s", ContainerStatusLabel(item), ContainerStatusText(item))
+		if item.Snapshot {
+			name = "  snapshot"
+			ctrStatus = ""
+		}
 		rows = append(rows, []string{
-			item.Name,
+			name,
 			item.ImageRef,
-			fmt.Sprintf("%s %s", ImageStatusLabel(item.ImagePresent), ImageStatusText(item.ImagePresent)),
+			imageStatus(item),
 			item.ContainerName,
-			fmt.Sprintf("%s %s", ContainerStatusLabel(item), ContainerStatusText(item)),
+			ctrStatus,
 		})
 	}
 
+	headers := []string{"Alias", "Image", "Status", "Container", "Status"}
+	return renderTable(headers, rows, width, colImgStatus, colCtrStatus)
+}
+
+// renderOutdatedTable renders the image update table.
+func renderOutdatedTable(items []service.ImageUpdate, width int) string {
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{
+			item.Alias,
+			item.Ref,
+			shortDigest(item.LocalDigest),
+			shortDigest(item.RemoteDigest),
+			fmt.Sprintf("%s %s", UpdateStatusLabel(item.Status), item.Status),
+		})
+	}
+	return renderTable([]string{"Alias", "Image", "Local", "Remote", "Status"}, rows, width, colUpdateStatus)
+}
+
+// renderTable renders rows with the shared zebra style. statusCols get a fixed width.
+// If width > 0, the table is constrained to that width and wrapping is enabled.
+func renderTable(headers []string, rows [][]string, width int, statusCols ...int) string {
 	var (
 		purple    = lipgloss.Color("99")
 		gray      = lipgloss.Color("245")
@@ -175,7 +416,7 @@ func renderAliasStatusTable(items []service.AliasStatus, width int) string {
 	styleFor := func(row, col int) lipgloss.Style {
 		if row == table.HeaderRow {
 			s := headerCell
-			if isStatusCol(col) {
+			if slices.Contains(statusCols, col) {
 				s = s.Width(statusColWidth).Align(lipgloss.Center)
 			}
 			return s
@@ -188,7 +429,7 @@ func renderAliasStatusTable(items []service.AliasStatus, width int) string {
 			s = oddRowCell
 		}
 
-		if isStatusCol(col) {
+		if slices.Contains(statusCols, col) {
 			return s.Width(statusColWidth).Align(lipgloss.Left)
 		}
 		return s.Align(lipgloss.Left)
@@ -198,7 +439,7 @@ func renderAliasStatusTable(items []service.AliasStatus, width int) string {
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(purple)).
 		StyleFunc(styleFor).
-		Headers("Alias", "Image", "Status", "Container", "Status").
+		Headers(headers...).
 		Rows(rows...)
 
 	if width > 0 {
@@ -2,6 +2,7 @@ package render_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"strings"
@@ -86,17 +87,164 @@ func TestListStatusesTable(t *testing.T) {
 	}
 }
 
+func TestListStatusesInstances(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+
+	r.ListStatuses([]service.AliasStatus{
+		{Name: "code", ImageRef: "code:latest", ContainerName: "cradle-code"},
+		{
+			Name:             "code",
+			Instance:         "b",
+			ImageRef:         "code:latest",
+			ContainerName:    "cradle-code-b",
+			ContainerPresent: true,
+			ContainerStatus:  "running",
+		},
+	})
+
+	out := buf.String()
+	for _, s := range []string{"@b", "cradle-code-b"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in output:\n%s", s, out)
+		}
+	}
+	if strings.Index(out, "cradle-code ") > strings.Index(out, "@b") {
+		t.Fatalf("expected instance row under its alias:\n%s", out)
+	}
+}
+
+func TestListStatusesSnapshotRows(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+
+	r.ListStatuses([]service.AliasStatus{
+		{Name: "code", ImageRef: "cradle/code:latest", ImagePresent: true, ContainerName: "cradle-code"},
+		{Name: "code", Snapshot: true, ImageRef: "cradle/code:snapshot-20261018-101500", ImagePresent: true},
+	})
+
+	out := buf.String()
+	for _, s := range []string{"snapshot", "cradle/code:snapshot-20261018-101500"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in output:\n%s", s, out)
+		}
+	}
+	if strings.Count(out, "missing") != 1 {
+		t.Fatalf("expected no container status on the snapshot row:\n%s", out)
+	}
+}
+
+func TestListStatusesBundleState(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+
+	r.ListStatuses([]service.AliasStatus{
+		{Name: "code", ImageRef: "cradle/code:latest", ImagePresent: true, Bundle: service.BundleMatch},
+		{Name: "web", ImageRef: "nginx:1.27", ImagePresent: true, Bundle: service.BundleMismatch},
+	})
+
+	out := buf.String()
+	for _, s := range []string{"bundled", "mismatch"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in output:\n%s", s, out)
+		}
+	}
+}
+
+func TestOutdatedFiltersCurrent(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+	items := []service.ImageUpdate{
+		{Alias: "base", Ref: "ubuntu:24.04", Status: service.UpdateCurrent},
+		{
+			Alias:        "web",
+			Ref:          "nginx:1.27",
+			LocalDigest:  "sha256:0123456789abcdef",
+			RemoteDigest: "sha256:fedcba9876543210",
+			Status:       service.UpdateAvailable,
+		},
+	}
+
+	r.Outdated(items, false)
+	out := buf.String()
+	if strings.Contains(out, "ubuntu:24.04") || !strings.Contains(out, "nginx:1.27") {
+		t.Fatalf("expected only the outdated alias:\n%s", out)
+	}
+	if !strings.Contains(out, "sha256:0123456789ab") || strings.Contains(out, "sha256:0123456789abc") {
+		t.Fatalf("expected shortened digests:\n%s", out)
+	}
+
+	buf.Reset()
+	r.Outdated(items[:1], false)
+	if !strings.Contains(buf.String(), "up to date") {
+		t.Fatalf("expected up to date message, got %q", buf.String())
+	}
+
+	buf.Reset()
+	r.Outdated(items, true)
+	if !strings.Contains(buf.String(), "ubuntu:24.04") {
+		t.Fatalf("expected all aliases with all set:\n%s", buf.String())
+	}
+}
+
 func TestRunStartStopAndBuildStart(_ *testing.T) {
 	log := slog.New(slog.DiscardHandler)
 	r := render.New(log, io.Discard)
 
 	r.RunStart("id")
 	r.RunStop("id")
+	r.RunRemove("id")
 
 	r.BuildStart(service.AliasInfo{Kind: service.ImagePull, Ref: "ubuntu:24.04"})
 	r.BuildStart(service.AliasInfo{Kind: service.ImageBuild, Tag: "cradle/test:latest", Cwd: "/tmp"})
 }
 
+func TestRunPortsAndJSON(t *testing.T) {
+	var logs bytes.Buffer
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.NewTextHandler(&logs, nil)), &buf)
+
+	ports := []service.PublishedPort{
+		{ContainerPort: "80", Protocol: "tcp", HostIP: "0.0.0.0", HostPort: "49153", URL: "http://localhost:49153"},
+	}
+	r.RunPorts(ports)
+	if !strings.Contains(logs.String(), "url=http://localhost:49153") {
+		t.Fatalf("expected url in port log, got %q", logs.String())
+	}
+
+	if err := r.RunJSON(&service.RunResult{ID: "abc", Name: "cradle-web", Ports: ports}); err != nil {
+		t.Fatalf("RunJSON error: %v", err)
+	}
+	var decoded struct {
+		ID    string                  `json:"id"`
+		Ports []service.PublishedPort `json:"ports"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+	if decoded.ID != "abc" || len(decoded.Ports) != 1 || decoded.Ports[0].HostPort != "49153" {
+		t.Fatalf("unexpected json: %s", buf.String())
+	}
+}
+
+func TestPortList(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+
+	r.PortList(nil)
+	if !strings.Contains(buf.String(), "No published ports.") {
+		t.Fatalf("expected empty message, got %q", buf.String())
+	}
+
+	buf.Reset()
+	r.PortList([]service.PublishedPort{
+		{ContainerPort: "8443", Protocol: "tcp", HostIP: "0.0.0.0", HostPort: "8443", URL: "https://localhost:8443/"},
+	})
+	if got := buf.String(); got != "8443/tcp -> 0.0.0.0:8443  https://localhost:8443/\n" {
+		t.Fatalf("unexpected port list: %q", got)
+	}
+}
+
 func TestContainerStatusLabelVariants(t *testing.T) {
 	statuses := map[string]string{
 		"running":    "▶️",
@@ -123,3 +271,23 @@ func TestContainerStatusLabelVariants(t *testing.T) {
 		t.Fatalf("missing container: got %q", got)
 	}
 }
+
+func TestDoctorReport(t *testing.T) {
+	var buf bytes.Buffer
+	r := render.New(slog.New(slog.DiscardHandler), &buf)
+	r.Doctor([]service.Check{
+		{Name: "engine", Status: service.CheckPass, Detail: "reachable", Fix: "unused"},
+		{Name: "buildkit", Status: service.CheckWarn, Detail: "classic builder", Fix: "enable BuildKit"},
+		{Name: "config", Status: service.CheckFail, Detail: "missing"},
+	})
+	out := buf.String()
+	for _, want := range []string{"pass engine: reachable", "warn buildkit: classic builder", "fix: enable BuildKit",
+		"fail config: missing", "1 passed, 1 warnings, 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in report:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "unused") {
+		t.Fatalf("expected no fix for passing checks:\n%s", out)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"os"
 	"strings"
@@ -49,7 +50,7 @@ func pullImage(
 		}
 	}()
 
-	return renderDockerJSON(out, resp)
+	return RenderDockerJSON(out, resp)
 }
 
 func PullOptionsFromSpec(spec *config.PullSpec) (client.ImagePullOptions, error) {
@@ -67,15 +68,33 @@ func PullOptionsFromSpec(spec *config.PullSpec) (client.ImagePullOptions, error)
 	if spec.Auth == nil {
 		return options, nil
 	}
-	authJSON, err := json.Marshal(registryAuthPayload(*spec.Auth))
+	auth, err := encodeRegistryAuth(*spec.Auth)
 	if err != nil {
 		return options, err
 	}
-	options.RegistryAuth = base64.StdEncoding.EncodeToString(authJSON)
+	options.RegistryAuth = auth
 	return options, nil
 }
 
-func buildImage(ctx context.Context, cli *client.Client, b *config.BuildSpec, tag string, out io.Writer) error {
+// encodeRegistryAuth returns spec in the base64 JSON form of the X-Registry-Auth header.
+func encodeRegistryAuth(spec config.RegistryAuthSpec) (string, error) {
+	authJSON, err := json.Marshal(registryAuthPayload(spec))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(authJSON), nil
+}
+
+// buildImage builds b as tag. Builds with a local cache go through the BuildKit
+// control API, since the build endpoint only exports inline cache.
+func buildImage(
+	ctx context.Context,
+	cli *client.Client,
+	log *slog.Logger,
+	b *config.BuildSpec,
+	tag string,
+	out io.Writer,
+) error {
 	if b == nil {
 		return errors.New("missing build spec")
 	}
@@ -85,12 +104,32 @@ func buildImage(ctx context.Context, cli *client.Client, b *config.BuildSpec, ta
 	if err != nil {
 		return err
 	}
+	if b.Git != nil {
+		if contextDir, err = PrepareGitContext(ctx, b.Git, GitCacheDir()); err != nil {
+			return err
+		}
+	}
+	generated, err := GeneratedDockerfile(b)
+	if err != nil {
+		return err
+	}
+	var files map[string][]byte
+	if generated != "" {
+		files = map[string][]byte{generatedDockerfileName: []byte(generated)}
+	}
 
-	if buildErr := runImageBuild(ctx, cli, contextDir, opts.Dockerfile, opts, out); buildErr != nil {
+	if hasLocalCache(b) {
+		return solveImage(ctx, cli, contextDir, files, b, opts, out)
+	}
+	if buildErr := runImageBuild(ctx, cli, contextDir, files, opts, out); buildErr != nil {
 		if strings.Contains(buildErr.Error(), "no active sessions") {
+			log.Debug("falling back to the v1 builder", "tag", tag, "error", buildErr)
 			opts.Version = build.BuilderV1
 			opts.Platforms = nil
-			return runImageBuild(ctx, cli, contextDir, opts.Dockerfile, opts, out)
+			if _, set := b.Args[inlineCacheArg]; !set {
+				delete(opts.BuildArgs, inlineCacheArg)
+			}
+			return runImageBuild(ctx, cli, contextDir, files, opts, out)
 		}
 		return buildErr
 	}
@@ -107,12 +146,19 @@ func BuildOptionsFromSpec(b *config.BuildSpec, tag string) (client.ImageBuildOpt
 	if dockerfile == "" {
 		dockerfile = "Dockerfile"
 	}
+	if b.DockerfileInline != "" || b.Recipe != nil {
+		dockerfile = generatedDockerfileName
+	}
 
 	buildArgs := map[string]*string{}
 	for k, v := range b.Args {
 		vv := v
 		buildArgs[k] = &vv
 	}
+	if _, ok := buildArgs[inlineCacheArg]; !ok && len(b.CacheTo) > 0 {
+		enabled := "1"
+		buildArgs[inlineCacheArg] = &enabled
+	}
 
 	tags := []string{tag}
 	if len(b.Tags) > 0 {
@@ -159,7 +205,7 @@ func BuildOptionsFromSpec(b *config.BuildSpec, tag string) (client.ImageBuildOpt
 		NoCache:     b.NoCache,
 		PullParent:  b.PullParent,
 		Squash:      b.Squash,
-		CacheFrom:   b.CacheFrom,
+		CacheFrom:   registryCacheRefs(b.CacheFrom),
 		SecurityOpt: b.SecurityOpt,
 		Platforms:   platforms,
 
@@ -172,6 +218,18 @@ func BuildOptionsFromSpec(b *config.BuildSpec, tag string) (client.ImageBuildOpt
 	}, nil
 }
 
+// registryCacheRefs returns the registry refs among caches; the daemon's build API
+// takes cache sources as plain image references.
+func registryCacheRefs(caches []config.CacheSpec) []string {
+	var refs []string
+	for _, c := range caches {
+		if c.Type == config.CacheTypeRegistry || c.Type == "" {
+			refs = append(refs, c.Ref)
+		}
+	}
+	return refs
+}
+
 func ParsePlatformList(specs []string) ([]ocispec.Platform, error) {
 	if len(specs) == 0 {
 		return nil, nil
@@ -190,7 +248,8 @@ func ParsePlatformList(specs []string) ([]ocispec.Platform, error) {
 func runImageBuild(
 	ctx context.Context,
 	cli *client.Client,
-	contextDir, dockerfile string,
+	contextDir string,
+	files map[string][]byte,
 	opts client.ImageBuildOptions,
 	out io.Writer,
 ) (err error) {
@@ -198,7 +257,7 @@ func runImageBuild(
 	if opts.RemoteContext != "" {
 		tar = io.NopCloser(strings.NewReader(""))
 	} else {
-		tar = TarDir(contextDir)
+		tar = TarContext(contextDir, files)
 	}
 	defer func() {
 		if cerr := tar.Close(); err == nil && cerr != nil {
@@ -206,8 +265,6 @@ func runImageBuild(
 		}
 	}()
 
-	opts.Dockerfile = dockerfile
-
 	var sess *session.Session
 	var sessErrCh chan error
 	if opts.Version == build.BuilderBuildKit {
@@ -240,7 +297,7 @@ func runImageBuild(
 		}
 	}()
 
-	return renderDockerJSON(out, res.Body)
+	return RenderDockerJSON(out, res.Body)
 }
 
 func buildUlimits(specs []config.UlimitSpec) []*container.Ulimit {
@@ -318,22 +375,36 @@ func buildOutputs(specs []config.BuildOutputSpec) []client.ImageBuildOutput {
 }
 
 type buildMessage struct {
-	ID       string          `json:"id,omitempty"`
-	Status   string          `json:"status,omitempty"`
-	Progress string          `json:"progress,omitempty"`
-	Stream   string          `json:"stream,omitempty"`
-	Error    string          `json:"error,omitempty"`
-	Aux      json.RawMessage `json:"aux,omitempty"`
+	ID             string          `json:"id,omitempty"`
+	Status         string          `json:"status,omitempty"`
+	Progress       string          `json:"progress,omitempty"`
+	ProgressDetail progressDetail  `json:"progressDetail,omitempty"`
+	Stream         string          `json:"stream,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	Aux            json.RawMessage `json:"aux,omitempty"`
 }
 
-func renderDockerJSON(out io.Writer, in io.Reader) error {
+// progressDetail holds the byte counts behind a progress bar.
+type progressDetail struct {
+	Current int64 `json:"current,omitempty"`
+	Total   int64 `json:"total,omitempty"`
+}
+
+// RenderDockerJSON renders a Docker JSON message stream from a pull, build or load.
+// BuildKit progress gets a live view on color terminals and plain lines otherwise.
+func RenderDockerJSON(out io.Writer, in io.Reader) (err error) {
 	renderer := newDockerRenderer(out)
+	defer func() {
+		if ferr := renderer.finish(); err == nil && ferr != nil {
+			err = ferr
+		}
+	}()
 	scanner := bufio.NewScanner(in)
 	buf := make([]byte, 0, scannerBufferSize)
 	scanner.Buffer(buf, scannerMaxTokenSize)
 
 	for scanner.Scan() {
-		if err := renderer.process(scanner.Bytes()); err != nil {
+		if err = renderer.process(scanner.Bytes()); err != nil {
 			return err
 		}
 	}
@@ -342,18 +413,39 @@ func renderDockerJSON(out io.Writer, in io.Reader) error {
 }
 
 type dockerRenderer struct {
-	style     OutStyle
-	out       io.Writer
-	last      map[string]string
-	lastTrace string
+	style OutStyle
+	out   io.Writer
+	last  map[string]string
+	// view shows BuildKit progress: live on a color terminal, plain otherwise.
+	view progressView
+	// pull keeps one live line per layer on a color terminal; it is nil in line mode.
+	pull *pullView
+
+	// log receives every event unstyled, with BuildKit steps in plain form.
+	log       *BuildLog
+	logView   *plainProgress
+	logStatus map[string]string
 }
 
 func newDockerRenderer(out io.Writer) *dockerRenderer {
-	return &dockerRenderer{
-		style: OutputStyle(out),
-		out:   out,
-		last:  map[string]string{},
+	out, log := splitOutput(out)
+	r := &dockerRenderer{
+		style:     OutputStyle(out),
+		out:       out,
+		last:      map[string]string{},
+		log:       log,
+		logStatus: map[string]string{},
+	}
+	r.view = newPlainProgress(out)
+	if r.style.Color {
+		size := func() (int, int) { return terminalSize(out) }
+		r.view = newLiveProgress(out, size)
+		r.pull = newPullView(out, size)
 	}
+	if log != nil {
+		r.logView = newPlainProgress(log)
+	}
+	return r
 }
 
 func (r *dockerRenderer) process(line []byte) error {
@@ -364,10 +456,15 @@ func (r *dockerRenderer) process(line []byte) error {
 
 	var msg buildMessage
 	if err := json.Unmarshal(line, &msg); err != nil {
-		_, writeErr := fmt.Fprintln(r.out, trimmed)
-		return writeErr
+		if logErr := r.log.Printf("%s", trimmed); logErr != nil {
+			return logErr
+		}
+		return r.print(trimmed + "\n")
 	}
 
+	if err := r.logMessage(msg); err != nil {
+		return err
+	}
 	return r.dispatch(msg)
 }
 
@@ -376,7 +473,7 @@ func (r *dockerRenderer) dispatch(msg buildMessage) error {
 	case msg.Error != "":
 		return fmt.Errorf("%s", msg.Error)
 	case msg.Stream != "":
-		return writeString(r.out, r.style.Prefixed(msg.Stream))
+		return r.print(r.style.Prefixed(msg.Stream))
 	case msg.ID == "moby.buildkit.trace" && len(msg.Aux) > 0:
 		return r.handleTrace(msg.Aux)
 	case msg.Status != "":
@@ -386,23 +483,46 @@ func (r *dockerRenderer) dispatch(msg buildMessage) error {
 	}
 }
 
+// logMessage writes msg to the build log. Pull progress bars are left out; every
+// other status change is kept. BuildKit traces are logged by handleTrace.
+func (r *dockerRenderer) logMessage(msg buildMessage) error {
+	if r.log == nil {
+		return nil
+	}
+	switch {
+	case msg.Error != "":
+		return r.log.Printf("ERROR: %s", msg.Error)
+	case msg.Stream != "":
+		_, err := r.log.Write([]byte(msg.Stream))
+		return err
+	case msg.ID == "moby.buildkit.trace":
+		return nil
+	case msg.Status != "":
+		if r.logStatus[msg.ID] == msg.Status {
+			return nil
+		}
+		r.logStatus[msg.ID] = msg.Status
+		return r.log.Printf("%s", strings.Join(FilterEmpty([]string{LabelFor(msg.ID, msg.Status), msg.Status}), " "))
+	default:
+		return nil
+	}
+}
+
 func (r *dockerRenderer) handleTrace(raw json.RawMessage) error {
-	lines, ok := DecodeBuildkitTrace(raw)
+	sr, ok := decodeStatus(raw)
 	if !ok {
 		return nil
 	}
+	return r.handleStatusResponse(sr)
+}
 
-	for _, line := range lines {
-		if line == "" || line == r.lastTrace {
-			continue
-		}
-		r.lastTrace = line
-		if err := writeString(r.out, r.style.Line("🏗️", colorCyan, line)); err != nil {
+func (r *dockerRenderer) handleStatusResponse(sr *controlapi.StatusResponse) error {
+	if r.logView != nil {
+		if err := r.logView.update(sr); err != nil {
 			return err
 		}
 	}
-
-	return nil
+	return r.view.update(sr)
 }
 
 func (r *dockerRenderer) handleStatus(msg buildMessage) error {
@@ -412,15 +532,30 @@ func (r *dockerRenderer) handleStatus(msg buildMessage) error {
 	}
 	r.last[msg.ID] = key
 
+	if r.pull != nil && LooksLayerID(msg.ID) {
+		return r.pull.update(msg)
+	}
 	label := LabelFor(msg.ID, msg.Status)
 	switch {
 	case msg.Progress != "":
-		return writeString(r.out, r.style.Line("📦", colorYellow, label, msg.Status, msg.Progress))
+		return r.print(r.style.Line("📦", colorYellow, label, msg.Status, msg.Progress))
 	case msg.ID != "":
-		return writeString(r.out, r.style.Line(StatusEmoji(msg.Status), colorCyan, label, msg.Status))
+		return r.print(r.style.Line(StatusEmoji(msg.Status), colorCyan, label, msg.Status))
 	default:
-		return writeString(r.out, r.style.Line(StatusEmoji(msg.Status), colorGreen, msg.Status))
+		return r.print(r.style.Line(StatusEmoji(msg.Status), colorGreen, msg.Status))
+	}
+}
+
+// print writes output above whichever live view is on screen.
+func (r *dockerRenderer) print(s string) error {
+	if r.pull != nil && r.pull.active() {
+		return r.pull.print(s)
 	}
+	return r.view.print(s)
+}
+
+func (r *dockerRenderer) finish() error {
+	return r.view.finish()
 }
 
 func writeString(out io.Writer, s string) error {
@@ -447,6 +582,25 @@ func OutputStyle(out io.Writer) OutStyle {
 	return OutStyle{Color: term.IsTerminal(fd)}
 }
 
+// terminalSize returns the columns and rows of the terminal behind out, or 80x24
+// when it cannot be read.
+func terminalSize(out io.Writer) (int, int) {
+	const defaultWidth, defaultHeight = 80, 24
+	f, ok := out.(interface{ Fd() uintptr })
+	if !ok {
+		return defaultWidth, defaultHeight
+	}
+	fd, ok := termutil.Int(f.Fd())
+	if !ok {
+		return defaultWidth, defaultHeight
+	}
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+	return width, height
+}
+
 func (s OutStyle) Prefixed(text string) string {
 	if !s.Color {
 		return text
@@ -472,22 +626,13 @@ func FilterEmpty(parts []string) []string {
 	return out
 }
 
-func DecodeBuildkitTrace(raw json.RawMessage) ([]string, bool) {
+// decodeStatus decodes the BuildKit status update carried in a trace message.
+func decodeStatus(raw json.RawMessage) (*controlapi.StatusResponse, bool) {
 	dt, ok := decodeTrace(raw)
 	if !ok {
 		return nil, false
 	}
-
-	sr, ok := unmarshalStatus(dt)
-	if !ok {
-		return nil, false
-	}
-
-	lines := collectTraceLines(sr)
-	if len(lines) == 0 {
-		return nil, false
-	}
-	return lines, true
+	return unmarshalStatus(dt)
 }
 
 func decodeTrace(raw json.RawMessage) ([]byte, bool) {
@@ -506,47 +651,6 @@ func unmarshalStatus(data []byte) (*controlapi.StatusResponse, bool) {
 	return &sr, true
 }
 
-func collectTraceLines(sr *controlapi.StatusResponse) []string {
-	lines := make([]string, 0)
-	lines = append(lines, vertexLines(sr.GetVertexes())...)
-	lines = append(lines, logLines(sr.GetLogs())...)
-	lines = append(lines, warningLines(sr.GetWarnings())...)
-	return lines
-}
-
-func vertexLines(vertexes []*controlapi.Vertex) []string {
-	lines := make([]string, 0, len(vertexes))
-	for _, vtx := range vertexes {
-		name := strings.TrimSpace(vtx.GetName())
-		if name == "" {
-			continue
-		}
-		if vtx.GetCached() {
-			name += " (cached)"
-		}
-		if vtx.GetError() != "" {
-			name += " (error: " + strings.TrimSpace(vtx.GetError()) + ")"
-		}
-		lines = append(lines, name)
-	}
-	return lines
-}
-
-func logLines(logs []*controlapi.VertexLog) []string {
-	lines := make([]string, 0)
-	for _, log := range logs {
-		text := string(log.GetMsg())
-		for line := range strings.SplitSeq(text, "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			lines = append(lines, line)
-		}
-	}
-	return lines
-}
-
 func warningLines(warnings []*controlapi.VertexWarning) []string {
 	lines := make([]string, 0)
 	for _, warn := range warnings {
@@ -616,6 +720,7 @@ const (
 	colorReset  = "\x1b[0m"
 	colorDim    = "\x1b[2m"
 	colorGreen  = "\x1b[32m"
+	colorRed    = "\x1b[31m"
 	colorYellow = "\x1b[33m"
 	colorCyan   = "\x1b[36m"
 )
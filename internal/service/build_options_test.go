@@ -94,7 +94,7 @@ func TestBuildOptionsFromSpecResources(t *testing.T) {
 		ShmSize:      64 * 1024 * 1024,
 		PullParent:   true,
 		NoCache:      true,
-		CacheFrom:    []string{"cache:latest"},
+		CacheFrom:    []config.CacheSpec{{Ref: "cache:latest"}},
 		Squash:       true,
 	}
 
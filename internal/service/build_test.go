@@ -1,14 +1,10 @@
 package service_test
 
 import (
-	"encoding/json"
 	"io"
 	"strings"
 	"testing"
 
-	"github.com/containerd/containerd/v2/pkg/protobuf/proto"
-	controlapi "github.com/moby/buildkit/api/services/control"
-
 	"github.com/rhajizada/cradle/internal/service"
 )
 
@@ -64,39 +60,6 @@ func TestLooksLayerID(t *testing.T) {
 	}
 }
 
-func TestDecodeBuildkitTrace(t *testing.T) {
-	sr := &controlapi.StatusResponse{
-		Vertexes: []*controlapi.Vertex{
-			{Name: "step 1"},
-		},
-		Logs: []*controlapi.VertexLog{
-			{Msg: []byte("hello\nworld\n")},
-		},
-		Warnings: []*controlapi.VertexWarning{
-			{Short: []byte("warn")},
-		},
-	}
-
-	raw, err := proto.Marshal(sr)
-	if err != nil {
-		t.Fatalf("marshal status: %v", err)
-	}
-	data, err := json.Marshal(raw)
-	if err != nil {
-		t.Fatalf("marshal raw: %v", err)
-	}
-
-	lines, ok := service.DecodeBuildkitTrace(data)
-	if !ok || len(lines) == 0 {
-		t.Fatalf("expected decoded lines")
-	}
-
-	joined := strings.Join(lines, " ")
-	if !strings.Contains(joined, "step 1") || !strings.Contains(joined, "hello") || !strings.Contains(joined, "warn") {
-		t.Fatalf("unexpected decoded lines: %v", lines)
-	}
-}
-
 func TestParsePlatformList(t *testing.T) {
 	plats, err := service.ParsePlatformList([]string{"linux/amd64", "linux/arm64"})
 	if err != nil {
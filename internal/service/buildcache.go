@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/client"
+)
+
+const (
+	dockerHubHost = "docker.io"
+	// inlineCacheArg makes BuildKit embed cache metadata in the image config, which is
+	// what lets a pushed image seed later builds.
+	inlineCacheArg = "BUILDKIT_INLINE_CACHE"
+)
+
+// cacheDir returns the cradle directory name under $XDG_CACHE_HOME.
+func cacheDir(name string) string {
+	if xdg, ok := os.LookupEnv("XDG_CACHE_HOME"); ok && xdg != "" {
+		return filepath.Join(xdg, "cradle", name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return filepath.Join(os.TempDir(), "cradle", name)
+	}
+	return filepath.Join(home, ".cache", "cradle", name)
+}
+
+// BuildCacheDir returns the default local cache directory of alias.
+func BuildCacheDir(alias string) string {
+	return filepath.Join(cacheDir("buildcache"), alias)
+}
+
+// LocalCacheSpec returns b with the src and dest of its local cache entries defaulted
+// to the alias cache directory. b is copied only when an entry changes.
+func LocalCacheSpec(alias string, b *config.BuildSpec) *config.BuildSpec {
+	if !hasLocalCache(b) {
+		return b
+	}
+	spec := *b
+	spec.CacheFrom = slices.Clone(b.CacheFrom)
+	spec.CacheTo = slices.Clone(b.CacheTo)
+	for i, c := range spec.CacheFrom {
+		if c.Type == config.CacheTypeLocal && c.Src == "" {
+			spec.CacheFrom[i].Src = BuildCacheDir(alias)
+		}
+	}
+	for i, c := range spec.CacheTo {
+		if c.Type == config.CacheTypeLocal && c.Dest == "" {
+			spec.CacheTo[i].Dest = BuildCacheDir(alias)
+		}
+	}
+	return &spec
+}
+
+// hasLocalCache reports whether b imports or exports a local cache, which only the
+// BuildKit control API supports.
+func hasLocalCache(b *config.BuildSpec) bool {
+	isLocal := func(c config.CacheSpec) bool { return c.Type == config.CacheTypeLocal }
+	return slices.ContainsFunc(b.CacheFrom, isLocal) || slices.ContainsFunc(b.CacheTo, isLocal)
+}
+
+// exportBuildCache pushes the freshly built tag to every registry cache_to entry.
+// Local and inline entries are written by BuildKit during the build.
+func (s *Service) exportBuildCache(ctx context.Context, b *config.BuildSpec, tag string, out io.Writer) error {
+	for _, c := range b.CacheTo {
+		if c.Type != config.CacheTypeRegistry {
+			continue
+		}
+		if err := s.pushCacheImage(ctx, b, tag, c.Ref, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushCacheImage tags the built image as ref and pushes it, using the auth_configs
+// entry of ref's registry when there is one.
+func (s *Service) pushCacheImage(ctx context.Context, b *config.BuildSpec, tag, ref string, out io.Writer) error {
+	if err := s.tagImage(ctx, tag, ref); err != nil {
+		return err
+	}
+	auth, err := buildRegistryAuth(b, ref)
+	if err != nil {
+		return err
+	}
+	resp, err := s.cli.ImagePush(ctx, ref, client.ImagePushOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("push build cache %s: %w", ref, err)
+	}
+	defer resp.Close()
+	return RenderDockerJSON(out, resp)
+}
+
+// buildRegistryAuth encodes the auth_configs entry of ref's registry, or returns ""
+// when b has none.
+func buildRegistryAuth(b *config.BuildSpec, ref string) (string, error) {
+	spec, ok := registryAuthFor(b.AuthConfigs, ref)
+	if !ok {
+		return "", nil
+	}
+	return encodeRegistryAuth(spec)
+}
+
+// registryAuthFor finds the auth_configs entry for the registry of ref.
+func registryAuthFor(auths map[string]config.RegistryAuthSpec, ref string) (config.RegistryAuthSpec, bool) {
+	return registryAuthForHost(auths, RegistryHost(ref))
+}
+
+// registryAuthForHost finds the auth_configs entry for host. Docker Hub entries may
+// be keyed by any of its usual server names.
+func registryAuthForHost(auths map[string]config.RegistryAuthSpec, host string) (config.RegistryAuthSpec, bool) {
+	keys := []string{host, "https://" + host}
+	if host == dockerHubHost {
+		keys = append(keys, "index.docker.io", "https://index.docker.io/v1/")
+	}
+	for _, key := range keys {
+		if spec, ok := auths[key]; ok {
+			return spec, true
+		}
+	}
+	return config.RegistryAuthSpec{}, false
+}
+
+// RegistryHost returns the registry of an image reference; references without a
+// host component belong to Docker Hub.
+func RegistryHost(ref string) string {
+	first, _, ok := strings.Cut(ref, "/")
+	if !ok || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return dockerHubHost
+	}
+	return first
+}
@@ -0,0 +1,212 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+
+	bkclient "github.com/moby/buildkit/client"
+)
+
+func TestBuildOptionsFromSpecCache(t *testing.T) {
+	spec := &config.BuildSpec{
+		CacheFrom: []config.CacheSpec{
+			{Type: config.CacheTypeRegistry, Ref: "ghcr.io/org/app:cache"},
+			{Type: config.CacheTypeLocal, Src: "/cache/dev"},
+		},
+		CacheTo: []config.CacheSpec{{Type: config.CacheTypeInline}},
+	}
+	opts, err := service.BuildOptionsFromSpec(spec, "demo:latest")
+	if err != nil {
+		t.Fatalf("BuildOptionsFromSpec error: %v", err)
+	}
+	if len(opts.CacheFrom) != 1 || opts.CacheFrom[0] != "ghcr.io/org/app:cache" {
+		t.Fatalf("expected only registry cache sources, got %+v", opts.CacheFrom)
+	}
+	if arg := opts.BuildArgs["BUILDKIT_INLINE_CACHE"]; arg == nil || *arg != "1" {
+		t.Fatalf("expected inline cache build arg, got %+v", opts.BuildArgs)
+	}
+
+	spec.CacheTo = nil
+	opts, err = service.BuildOptionsFromSpec(spec, "demo:latest")
+	if err != nil {
+		t.Fatalf("BuildOptionsFromSpec error: %v", err)
+	}
+	if _, ok := opts.BuildArgs["BUILDKIT_INLINE_CACHE"]; ok {
+		t.Fatalf("expected no inline cache build arg without cache_to")
+	}
+}
+
+func TestBuildCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+	if got := service.BuildCacheDir("dev"); got != "/tmp/xdg-cache/cradle/buildcache/dev" {
+		t.Fatalf("unexpected build cache dir: %q", got)
+	}
+}
+
+func TestLocalCacheSpec(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+	spec := &config.BuildSpec{
+		CacheFrom: []config.CacheSpec{{Type: config.CacheTypeLocal}, {Type: config.CacheTypeLocal, Src: "/src"}},
+		CacheTo:   []config.CacheSpec{{Type: config.CacheTypeLocal}},
+	}
+	got := service.LocalCacheSpec("dev", spec)
+	if got.CacheFrom[0].Src != "/tmp/xdg-cache/cradle/buildcache/dev" || got.CacheFrom[1].Src != "/src" {
+		t.Fatalf("unexpected cache_from: %+v", got.CacheFrom)
+	}
+	if got.CacheTo[0].Dest != "/tmp/xdg-cache/cradle/buildcache/dev" {
+		t.Fatalf("unexpected cache_to: %+v", got.CacheTo)
+	}
+	if spec.CacheFrom[0].Src != "" || spec.CacheTo[0].Dest != "" {
+		t.Fatalf("expected the config spec to stay unchanged, got %+v", spec)
+	}
+
+	registry := &config.BuildSpec{CacheFrom: []config.CacheSpec{{Type: config.CacheTypeRegistry, Ref: "a:b"}}}
+	if service.LocalCacheSpec("dev", registry) != registry {
+		t.Fatalf("expected a spec without local cache to be returned as is")
+	}
+}
+
+func TestSolveOptionsCache(t *testing.T) {
+	dir := t.TempDir()
+	spec := &config.BuildSpec{
+		Cwd:    dir,
+		Target: "runtime",
+		Args:   map[string]string{"GO_VERSION": "1.26"},
+		CacheFrom: []config.CacheSpec{
+			{Type: config.CacheTypeRegistry, Ref: "ghcr.io/org/app:cache"},
+			{Type: config.CacheTypeLocal, Src: "/cache/dev"},
+		},
+		CacheTo: []config.CacheSpec{
+			{Type: config.CacheTypeLocal, Dest: "/cache/dev"},
+			{Type: config.CacheTypeRegistry, Ref: "ghcr.io/org/app:cache"},
+		},
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+	opts, err := service.BuildOptionsFromSpec(spec, "cradle/dev:latest")
+	if err != nil {
+		t.Fatalf("BuildOptionsFromSpec error: %v", err)
+	}
+	solve, err := service.SolveOptions(spec, opts, dir, dir)
+	if err != nil {
+		t.Fatalf("SolveOptions error: %v", err)
+	}
+
+	wantAttrs := map[string]string{
+		"filename":                        "Dockerfile",
+		"target":                          "runtime",
+		"build-arg:GO_VERSION":            "1.26",
+		"build-arg:BUILDKIT_INLINE_CACHE": "1",
+		"platform":                        "linux/amd64,linux/arm64",
+	}
+	if !reflect.DeepEqual(solve.FrontendAttrs, wantAttrs) {
+		t.Fatalf("unexpected frontend attrs: %+v", solve.FrontendAttrs)
+	}
+	wantImports := []bkclient.CacheOptionsEntry{
+		{Type: "registry", Attrs: map[string]string{"ref": "ghcr.io/org/app:cache"}},
+		{Type: "local", Attrs: map[string]string{"src": "/cache/dev"}},
+	}
+	if !reflect.DeepEqual(solve.CacheImports, wantImports) {
+		t.Fatalf("unexpected cache imports: %+v", solve.CacheImports)
+	}
+	wantExports := []bkclient.CacheOptionsEntry{
+		{Type: "local", Attrs: map[string]string{"dest": "/cache/dev"}},
+		{Type: "inline"},
+	}
+	if !reflect.DeepEqual(solve.CacheExports, wantExports) {
+		t.Fatalf("unexpected cache exports: %+v", solve.CacheExports)
+	}
+	if len(solve.Exports) != 1 || solve.Exports[0].Type != "moby" ||
+		solve.Exports[0].Attrs["name"] != "cradle/dev:latest" {
+		t.Fatalf("unexpected exports: %+v", solve.Exports)
+	}
+	if solve.LocalMounts["context"] == nil || solve.LocalMounts["dockerfile"] == nil {
+		t.Fatalf("expected context and dockerfile mounts, got %+v", solve.LocalMounts)
+	}
+}
+
+// fakeV1Build rejects BuildKit builds without a session and records the build args
+// of the v1 build that follows.
+type fakeV1Build struct {
+	v1        bool
+	buildArgs map[string]*string
+}
+
+func (f *fakeV1Build) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/json"):
+		http.Error(w, `{"message":"no such image"}`, http.StatusNotFound)
+	case strings.HasSuffix(r.URL.Path, "/build") && r.URL.Query().Get("version") == "2":
+		http.Error(w, `{"message":"no active sessions"}`, http.StatusInternalServerError)
+	case strings.HasSuffix(r.URL.Path, "/build"):
+		f.v1 = true
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("buildargs")), &f.buildArgs)
+		_, _ = io.WriteString(w, `{"stream":"built\n"}`+"\n")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestBuildV1FallbackInlineCacheArg(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cases := []struct {
+		name string
+		args map[string]string
+		want bool
+	}{
+		{name: "added by cradle", want: false},
+		{name: "set by the user", args: map[string]string{"BUILDKIT_INLINE_CACHE": "1"}, want: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{Aliases: map[string]config.Alias{"dev": {Image: config.ImageSpec{
+				Build: &config.BuildSpec{
+					Cwd:     dir,
+					Args:    tc.args,
+					Policy:  config.ImagePolicyAlways,
+					CacheTo: []config.CacheSpec{{Type: config.CacheTypeInline}},
+				},
+			}}}}
+			daemon := &fakeV1Build{}
+			svc := newFakeDaemonService(t, daemon, cfg)
+			_, err := svc.EnsureImage(context.Background(), "dev", io.Discard, service.ImagePolicyOverrides{})
+			if err != nil {
+				t.Fatalf("EnsureImage: %v", err)
+			}
+			if !daemon.v1 {
+				t.Fatalf("expected a v1 build")
+			}
+			if _, got := daemon.buildArgs["BUILDKIT_INLINE_CACHE"]; got != tc.want {
+				t.Fatalf("expected inline cache arg %v in the v1 build, got %+v", tc.want, daemon.buildArgs)
+			}
+		})
+	}
+}
+
+func TestRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"ubuntu:24.04":              "docker.io",
+		"org/app:cache":             "docker.io",
+		"ghcr.io/org/app:cache":     "ghcr.io",
+		"localhost/app":             "localhost",
+		"registry.local:5000/app:1": "registry.local:5000",
+	}
+	for ref, want := range cases {
+		if got := service.RegistryHost(ref); got != want {
+			t.Fatalf("RegistryHost(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	buildLogDirPerm    = 0o755
+	buildLogFilePerm   = 0o644
+	buildLogTimeLayout = "20060102-150405"
+	buildLogLineLayout = "2006-01-02T15:04:05.000Z07:00"
+	// buildLogKeep is how many logs per alias survive in the default log directory.
+	buildLogKeep = 20
+)
+
+// stateDir returns the cradle path name under $XDG_STATE_HOME.
+func stateDir(name string) string {
+	if xdg, ok := os.LookupEnv("XDG_STATE_HOME"); ok && xdg != "" {
+		return filepath.Join(xdg, "cradle", name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return filepath.Join(os.TempDir(), "cradle", name)
+	}
+	return filepath.Join(home, ".local", "state", "cradle", name)
+}
+
+// BuildLogDir returns the directory that holds the pull and build logs of alias.
+func BuildLogDir(alias string) string {
+	return filepath.Join(stateDir("logs"), alias)
+}
+
+// BuildLogPath returns the log file of a pull or build of alias started at t.
+func BuildLogPath(alias string, t time.Time) string {
+	return filepath.Join(BuildLogDir(alias), t.UTC().Format(buildLogTimeLayout)+".log")
+}
+
+// BuildLog records the full, unstyled output of pulls and builds, one timestamped
+// line at a time. The file is created on the first write, so image checks that
+// have nothing to do leave no log behind. The log is best effort: the first failed
+// write logs a warning and turns it off. Methods on a nil BuildLog do nothing.
+type BuildLog struct {
+	path   string
+	header string
+	prune  bool
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	f        *os.File
+	created  bool
+	disabled bool
+	partial  []byte
+}
+
+// NewBuildLog returns a log that appends to path and starts with header.
+func NewBuildLog(path, header string) *BuildLog {
+	return &BuildLog{path: path, header: header, now: time.Now, logger: slog.New(slog.DiscardHandler)}
+}
+
+// newAliasBuildLog returns a log in the default directory of alias; creating it
+// removes the oldest logs beyond buildLogKeep.
+func newAliasBuildLog(alias, header string) *BuildLog {
+	l := NewBuildLog(BuildLogPath(alias, time.Now()), header)
+	l.prune = true
+	return l
+}
+
+// Path returns the log file path.
+func (l *BuildLog) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Created reports whether anything has been written to the log file.
+func (l *BuildLog) Created() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.created
+}
+
+// Printf writes a formatted line; embedded newlines start new timestamped lines.
+func (l *BuildLog) Printf(format string, args ...any) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writeLines(fmt.Sprintf(format, args...))
+	return nil
+}
+
+// Write timestamps every complete line of p. A trailing partial line is held until
+// the next write or Close.
+func (l *BuildLog) Write(p []byte) (int, error) {
+	if l == nil {
+		return len(p), nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.disabled {
+		return len(p), nil
+	}
+	data := slices.Concat(l.partial, p)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		l.partial = data
+		return len(p), nil
+	}
+	l.partial = slices.Clone(data[end+1:])
+	l.writeLines(string(data[:end]))
+	return len(p), nil
+}
+
+// Close flushes a pending partial line and closes the file.
+func (l *BuildLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.partial) > 0 {
+		l.writeLines(string(l.partial))
+		l.partial = nil
+	}
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	if err != nil {
+		return fmt.Errorf("build log: %w", err)
+	}
+	return nil
+}
+
+// annotate adds the log path to err when the log was written, so a failed build
+// points at its full output.
+func (l *BuildLog) annotate(err error) error {
+	if err == nil || !l.Created() {
+		return err
+	}
+	return fmt.Errorf("%w (log: %s)", err, l.path)
+}
+
+func (l *BuildLog) writeLines(text string) {
+	if l.disabled {
+		return
+	}
+	if err := l.open(); err != nil {
+		l.disable(err)
+		return
+	}
+	stamp := l.now().Format(buildLogLineLayout)
+	var b strings.Builder
+	for line := range strings.SplitSeq(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString(stamp + " " + strings.TrimRight(line, "\r") + "\n")
+	}
+	if _, err := io.WriteString(l.f, b.String()); err != nil {
+		l.disable(fmt.Errorf("build log: %w", err))
+	}
+}
+
+// disable turns the log off after a failed write so the pull or build carries on.
+func (l *BuildLog) disable(err error) {
+	l.disabled = true
+	l.partial = nil
+	if l.f != nil {
+		_ = l.f.Close()
+		l.f = nil
+	}
+	l.logger.Warn("build log disabled", "path", l.path, "error", err)
+}
+
+func (l *BuildLog) open() error {
+	if l.f != nil {
+		return nil
+	}
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, buildLogDirPerm); err != nil {
+		return fmt.Errorf("build log: %w", err)
+	}
+	if l.prune {
+		if err := pruneBuildLogs(dir, buildLogKeep-1); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, buildLogFilePerm)
+	if err != nil {
+		return fmt.Errorf("build log: %w", err)
+	}
+	l.f = f
+	l.created = true
+	if l.header != "" {
+		if _, err = fmt.Fprintf(f, "%s %s\n", l.now().Format(buildLogLineLayout), l.header); err != nil {
+			return fmt.Errorf("build log: %w", err)
+		}
+	}
+	return nil
+}
+
+// pruneBuildLogs removes the oldest .log files in dir until keep remain. Log names
+// are timestamps, so name order is age order.
+func pruneBuildLogs(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("build log: %w", err)
+	}
+	var logs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
+			logs = append(logs, entry.Name())
+		}
+	}
+	if len(logs) <= keep {
+		return nil
+	}
+	slices.Sort(logs)
+	for _, name := range logs[:len(logs)-keep] {
+		if err = os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("build log: %w", err)
+		}
+	}
+	return nil
+}
+
+// loggedOutput is the writer EnsureImage hands down while a build log is open.
+// Plain writes such as hook output go to both; the Docker renderer unwraps it to
+// style the terminal and log the full event stream separately.
+type loggedOutput struct {
+	out io.Writer
+	log *BuildLog
+}
+
+func (o *loggedOutput) Write(p []byte) (int, error) {
+	n, err := o.out.Write(p)
+	_, _ = o.log.Write(p[:n])
+	return n, err
+}
+
+// splitOutput returns the terminal writer and build log behind out.
+func splitOutput(out io.Writer) (io.Writer, *BuildLog) {
+	if o, ok := out.(*loggedOutput); ok {
+		return o.out, o.log
+	}
+	return out, nil
+}
@@ -0,0 +1,227 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/logging"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+var logLinePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}\S* `)
+
+func TestBuildLogPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	got := service.BuildLogPath("dev", time.Date(2026, 10, 18, 14, 16, 28, 0, time.UTC))
+	if got != "/tmp/xdg-state/cradle/logs/dev/20261018-141628.log" {
+		t.Fatalf("unexpected log path: %q", got)
+	}
+}
+
+func TestBuildLogLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "build.log")
+	log := service.NewBuildLog(path, "cradle build dev")
+	if log.Created() {
+		t.Fatalf("expected log to be created lazily")
+	}
+	if _, err := log.Write([]byte("step one\nstep ")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := log.Write([]byte("two\r\npartial")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := log.Printf("done in %ds", 3); err != nil {
+		t.Fatalf("printf: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	want := []string{"cradle build dev", "step one", "step two", "done in 3s", "partial"}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected log lines: %q", lines)
+	}
+	for i, line := range lines {
+		if !logLinePattern.MatchString(line) || logLinePattern.ReplaceAllString(line, "") != want[i] {
+			t.Fatalf("line %d: got %q, want timestamped %q", i, line, want[i])
+		}
+	}
+}
+
+func TestBuildLogUnusedLeavesNoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build.log")
+	if err := service.NewBuildLog(path, "header").Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no log file, got %v", err)
+	}
+}
+
+// fakePull serves an image pull stream and reports every image as missing.
+type fakePull struct {
+	stream string
+}
+
+func (f fakePull) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/images/create"):
+		_, _ = w.Write([]byte(f.stream))
+	case strings.Contains(r.URL.Path, "/images/"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No such image"}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func pullService(t *testing.T, stream string) *service.Service {
+	t.Helper()
+	return newFakeDaemonService(t, fakePull{stream: stream}, &config.Config{Aliases: map[string]config.Alias{
+		"base": {Image: config.ImageSpec{
+			Pull: &config.PullSpec{Ref: "ubuntu:24.04", Policy: config.ImagePolicyAlways},
+		}},
+	}})
+}
+
+func TestEnsureImageWritesBuildLog(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	svc := pullService(t, `{"status":"Pulling from library/ubuntu","id":"24.04"}
+{"status":"Downloading","progressDetail":{"current":1,"total":2},"progress":"[=>  ]","id":"a1b2c3d4e5f6"}
+{"status":"Downloading","progressDetail":{"current":2,"total":2},"progress":"[===>]","id":"a1b2c3d4e5f6"}
+{"status":"Pull complete","id":"a1b2c3d4e5f6"}
+{"status":"Status: Downloaded newer image for ubuntu:24.04"}
+`)
+	var out bytes.Buffer
+	if _, err := svc.EnsureImage(context.Background(), "base", &out, service.ImagePolicyOverrides{}); err != nil {
+		t.Fatalf("EnsureImage: %v", err)
+	}
+	if !strings.Contains(out.String(), "[===>]") {
+		t.Fatalf("expected progress on the terminal, got %q", out.String())
+	}
+
+	logs, err := filepath.Glob(filepath.Join(service.BuildLogDir("base"), "*.log"))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("expected one build log, got %v %v", logs, err)
+	}
+	data, err := os.ReadFile(logs[0])
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{"cradle pull base (ubuntu:24.04)", "layer a1b2c3d4e5f6: Pull complete",
+		"Status: Downloaded newer image"} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("expected %q in log:\n%s", want, log)
+		}
+	}
+	if strings.Count(log, "Downloading") != 1 || strings.Contains(log, "[=>") || strings.Contains(log, "\x1b[") {
+		t.Fatalf("expected one unstyled line per status change:\n%s", log)
+	}
+}
+
+func TestEnsureImageFailureNamesLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pull.log")
+	svc := pullService(t, `{"status":"Pulling from library/ubuntu","id":"24.04"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`)
+	overrides := service.ImagePolicyOverrides{LogFile: path}
+	_, err := svc.EnsureImage(context.Background(), "base", &bytes.Buffer{}, overrides)
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error naming %s, got %v", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "ERROR: manifest unknown") {
+		t.Fatalf("expected error in log, got %q %v", data, err)
+	}
+}
+
+func TestEnsureImageUnwritableLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	svc := pullService(t, `{"status":"Pulling from library/ubuntu","id":"24.04"}
+{"status":"Status: Downloaded newer image for ubuntu:24.04"}
+`)
+	var logs bytes.Buffer
+	svc.SetLogger(slog.New(logging.NewHandler(&logs, slog.LevelWarn)))
+	var out bytes.Buffer
+	overrides := service.ImagePolicyOverrides{LogFile: filepath.Join(file, "pull.log")}
+	if _, err := svc.EnsureImage(context.Background(), "base", &out, overrides); err != nil {
+		t.Fatalf("expected the pull to succeed without its log, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Downloaded newer image") {
+		t.Fatalf("expected progress on the terminal, got %q", out.String())
+	}
+	if strings.Count(logs.String(), "build log disabled") != 1 {
+		t.Fatalf("expected one warning, got:\n%s", logs.String())
+	}
+}
+
+func TestEnsureImageLogsDecisions(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	svc := pullService(t, `{"status":"Status: Image is up to date for ubuntu:24.04"}
+`)
+	var logs bytes.Buffer
+	svc.SetLogger(slog.New(logging.NewHandler(&logs, slog.LevelDebug)))
+	policy := config.ImagePolicyIfMissing
+	overrides := service.ImagePolicyOverrides{Pull: &policy}
+	if _, err := svc.EnsureImage(context.Background(), "base", &bytes.Buffer{}, overrides); err != nil {
+		t.Fatalf("EnsureImage: %v", err)
+	}
+	for _, want := range []string{
+		"image policy alias=base kind=pull policy=if_missing source=flag",
+		"image exists check ref=ubuntu:24.04 exists=false",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected %q in debug log:\n%s", want, logs.String())
+		}
+	}
+
+	logs.Reset()
+	_, err := svc.EnsureImage(context.Background(), "base", &bytes.Buffer{}, service.ImagePolicyOverrides{})
+	if err != nil {
+		t.Fatalf("EnsureImage: %v", err)
+	}
+	want := "image policy alias=base kind=pull policy=always source=config"
+	if !strings.Contains(logs.String(), want) {
+		t.Fatalf("expected %q in debug log:\n%s", want, logs.String())
+	}
+}
+
+func TestEnsureImageLogsDefaultPolicy(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := &config.Config{Aliases: map[string]config.Alias{
+		"base": {Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}}},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	stream := `{"status":"Status: Image is up to date for ubuntu:24.04"}` + "\n"
+	svc := newFakeDaemonService(t, fakePull{stream: stream}, cfg)
+	var logs bytes.Buffer
+	svc.SetLogger(slog.New(logging.NewHandler(&logs, slog.LevelDebug)))
+	if _, err := svc.EnsureImage(context.Background(), "base", &bytes.Buffer{}, service.ImagePolicyOverrides{}); err != nil {
+		t.Fatalf("EnsureImage: %v", err)
+	}
+	want := "image policy alias=base kind=pull policy=always source=default"
+	if !strings.Contains(logs.String(), want) {
+		t.Fatalf("expected %q in debug log:\n%s", want, logs.String())
+	}
+}
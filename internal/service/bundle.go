@@ -0,0 +1,350 @@
+package service
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry names inside a bundle tarball, written in this order.
+const (
+	bundleManifestName = "manifest.json"
+	bundleConfigName   = "config.yaml"
+	bundleImagesName   = "images.tar"
+)
+
+const (
+	bundleVersion   = 1
+	bundleEntryMode = 0o644
+	bundleStatePerm = 0o600
+	bundleStateDir  = 0o755
+)
+
+// BundleImage records the image an alias resolved to when the bundle was saved.
+type BundleImage struct {
+	Alias   string `json:"alias"`
+	Ref     string `json:"ref"`
+	ImageID string `json:"image_id"`
+}
+
+// BundleManifest describes the contents of a bundle.
+type BundleManifest struct {
+	Version int           `json:"version"`
+	Images  []BundleImage `json:"images"`
+
+	// Config is the bundled config.yaml; Load fills it after validating it.
+	Config []byte `json:"-"`
+}
+
+// BundleState reports how an alias image compares to the last loaded bundle.
+type BundleState string
+
+const (
+	BundleNone     BundleState = ""
+	BundleMatch    BundleState = "match"
+	BundleMismatch BundleState = "mismatch"
+)
+
+// BundleStatePath returns the file recording images loaded from bundles.
+func BundleStatePath() string {
+	return stateDir("bundles.json")
+}
+
+// Save writes a bundle with the images of names and the aliases they depend on,
+// their config and a manifest to w. Every image must already exist locally.
+func (s *Service) Save(ctx context.Context, names []string, w io.Writer) (*BundleManifest, error) {
+	names, err := s.cfg.StartOrder(names)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BundleManifest{Version: bundleVersion}
+	snippet := &config.Config{Version: s.cfg.Version, Aliases: map[string]config.Alias{}}
+	refs := make([]string, 0, len(names))
+	for _, name := range names {
+		info, err := s.AliasInfo(name)
+		if err != nil {
+			return nil, err
+		}
+		ref := resolveImageRef(info)
+		inspect, err := s.cli.ImageInspect(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("image %s for %s: %w", ref, name, err)
+		}
+		manifest.Images = append(manifest.Images, BundleImage{Alias: name, Ref: ref, ImageID: inspect.ID})
+		snippet.Aliases[name] = s.cfg.Aliases[name]
+		s.addDeclared(snippet, s.cfg.Aliases[name].Run)
+		refs = append(refs, ref)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	configData, err := yaml.Marshal(snippet)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.stageImages(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = images.Close()
+		_ = os.Remove(images.Name())
+	}()
+
+	tw := tar.NewWriter(w)
+	if err = writeBundleEntry(tw, bundleManifestName, manifestData); err != nil {
+		return nil, err
+	}
+	if err = writeBundleEntry(tw, bundleConfigName, configData); err != nil {
+		return nil, err
+	}
+	stat, err := images.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err = tw.WriteHeader(bundleHeader(bundleImagesName, stat.Size())); err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(tw, images); err != nil {
+		return nil, fmt.Errorf("write %s: %w", bundleImagesName, err)
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addDeclared copies the declared networks and volumes run references into snippet
+// so the bundled config validates on its own.
+func (s *Service) addDeclared(snippet *config.Config, run config.RunSpec) {
+	for _, name := range ReferencedNetworks(s.cfg, run) {
+		if snippet.Networks == nil {
+			snippet.Networks = map[string]config.DeclaredNetworkSpec{}
+		}
+		snippet.Networks[name] = s.cfg.Networks[name]
+	}
+	for _, name := range ReferencedVolumes(s.cfg, run) {
+		if snippet.Volumes == nil {
+			snippet.Volumes = map[string]config.DeclaredVolumeSpec{}
+		}
+		snippet.Volumes[name] = s.cfg.Volumes[name]
+	}
+}
+
+// stageImages writes the image archive of refs to a temporary file so its size is
+// known before the tar header is written.
+func (s *Service) stageImages(ctx context.Context, refs []string) (*os.File, error) {
+	rc, err := s.cli.ImageSave(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("save images: %w", err)
+	}
+	defer rc.Close()
+
+	f, err := os.CreateTemp("", "cradle-images-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(f, rc); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("save images: %w", err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// Load reads a bundle from r, checks its config, loads its images, tags them as
+// cradle/<alias>:latest and records the manifest so ls can compare aliases against it.
+func (s *Service) Load(ctx context.Context, r io.Reader, out io.Writer) (*BundleManifest, error) {
+	var manifest *BundleManifest
+	var configData []byte
+	loaded := false
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		switch hdr.Name {
+		case bundleManifestName:
+			manifest, err = decodeBundleManifest(tr)
+			if err != nil {
+				return nil, err
+			}
+		case bundleConfigName:
+			if configData, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("read bundle: %w", err)
+			}
+		case bundleImagesName:
+			if manifest == nil {
+				return nil, fmt.Errorf("bundle: %s must precede %s", bundleManifestName, bundleImagesName)
+			}
+			if err = checkBundleConfig(configData, manifest); err != nil {
+				return nil, err
+			}
+			if err = s.loadImages(ctx, tr, out); err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("bundle: missing %s", bundleManifestName)
+	}
+	if !loaded {
+		return nil, fmt.Errorf("bundle: missing %s", bundleImagesName)
+	}
+	manifest.Config = configData
+
+	for _, img := range manifest.Images {
+		_, err := s.cli.ImageTag(ctx, client.ImageTagOptions{
+			Source: img.ImageID,
+			Target: "cradle/" + img.Alias + ":latest",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", img.Alias, err)
+		}
+	}
+	if err := RecordBundle(BundleStatePath(), manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (s *Service) loadImages(ctx context.Context, r io.Reader, out io.Writer) error {
+	resp, err := s.cli.ImageLoad(ctx, r)
+	if err != nil {
+		return fmt.Errorf("load images: %w", err)
+	}
+	defer resp.Close()
+	return RenderDockerJSON(out, resp)
+}
+
+// checkBundleConfig validates the structure of the bundled config, which must precede the images,
+// and checks that it defines every alias in the manifest.
+func checkBundleConfig(data []byte, manifest *BundleManifest) error {
+	if data == nil {
+		return fmt.Errorf("bundle: missing %s", bundleConfigName)
+	}
+	cfg, err := config.ParsePortable(data)
+	if err != nil {
+		return fmt.Errorf("bundle: %s: %w", bundleConfigName, err)
+	}
+	for _, img := range manifest.Images {
+		if _, ok := cfg.Aliases[img.Alias]; !ok {
+			return fmt.Errorf("bundle: %s has no alias %q", bundleConfigName, img.Alias)
+		}
+	}
+	return nil
+}
+
+func decodeBundleManifest(r io.Reader) (*BundleManifest, error) {
+	var manifest BundleManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("bundle: decode %s: %w", bundleManifestName, err)
+	}
+	if manifest.Version != bundleVersion {
+		return nil, fmt.Errorf("bundle: unsupported version %d", manifest.Version)
+	}
+	for _, img := range manifest.Images {
+		if img.Alias == "" || img.ImageID == "" {
+			return nil, fmt.Errorf("bundle: %s entry needs alias and image_id", bundleManifestName)
+		}
+	}
+	return &manifest, nil
+}
+
+// LoadedBundles reads the images recorded by previous loads, keyed by alias.
+// A missing state file yields an empty map.
+func LoadedBundles(path string) (map[string]BundleImage, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]BundleImage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var images []BundleImage
+	if err = json.Unmarshal(data, &images); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	out := make(map[string]BundleImage, len(images))
+	for _, img := range images {
+		out[img.Alias] = img
+	}
+	return out, nil
+}
+
+// RecordBundle merges the manifest images into the state file at path.
+func RecordBundle(path string, manifest *BundleManifest) error {
+	images, err := LoadedBundles(path)
+	if err != nil {
+		return err
+	}
+	for _, img := range manifest.Images {
+		images[img.Alias] = img
+	}
+	list := make([]BundleImage, 0, len(images))
+	for _, alias := range slices.Sorted(maps.Keys(images)) {
+		list = append(list, images[alias])
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), bundleStateDir); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, bundleStatePerm)
+}
+
+// CompareBundle reports whether imageID matches the image recorded for its alias.
+func CompareBundle(recorded BundleImage, ok bool, imageID string) BundleState {
+	if !ok || imageID == "" {
+		return BundleNone
+	}
+	if recorded.ImageID == imageID {
+		return BundleMatch
+	}
+	return BundleMismatch
+}
+
+func writeBundleEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(bundleHeader(name, int64(len(data)))); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func bundleHeader(name string, size int64) *tar.Header {
+	return &tar.Header{
+		Name:    name,
+		Mode:    bundleEntryMode,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+}
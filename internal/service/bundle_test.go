@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestBundleStatePath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	if got := service.BundleStatePath(); got != "/tmp/state/cradle/bundles.json" {
+		t.Fatalf("unexpected state path: %q", got)
+	}
+}
+
+func TestRecordBundleMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cradle", "bundles.json")
+
+	images, err := service.LoadedBundles(path)
+	if err != nil || len(images) != 0 {
+		t.Fatalf("expected empty state, got %v, %v", images, err)
+	}
+
+	first := &service.BundleManifest{Version: 1, Images: []service.BundleImage{
+		{Alias: "code", Ref: "cradle/code:latest", ImageID: "sha256:aaa"},
+		{Alias: "web", Ref: "nginx:1.27", ImageID: "sha256:bbb"},
+	}}
+	if err = service.RecordBundle(path, first); err != nil {
+		t.Fatalf("RecordBundle error: %v", err)
+	}
+	second := &service.BundleManifest{Version: 1, Images: []service.BundleImage{
+		{Alias: "code", Ref: "cradle/code:latest", ImageID: "sha256:ccc"},
+	}}
+	if err = service.RecordBundle(path, second); err != nil {
+		t.Fatalf("RecordBundle error: %v", err)
+	}
+
+	images, err = service.LoadedBundles(path)
+	if err != nil {
+		t.Fatalf("LoadedBundles error: %v", err)
+	}
+	if images["code"].ImageID != "sha256:ccc" || images["web"].ImageID != "sha256:bbb" {
+		t.Fatalf("unexpected merged state: %v", images)
+	}
+}
+
+func TestLoadedBundlesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundles.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.LoadedBundles(path); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestCompareBundle(t *testing.T) {
+	recorded := service.BundleImage{Alias: "code", ImageID: "sha256:aaa"}
+	cases := []struct {
+		ok   bool
+		id   string
+		want service.BundleState
+	}{
+		{ok: false, id: "sha256:aaa", want: service.BundleNone},
+		{ok: true, id: "", want: service.BundleNone},
+		{ok: true, id: "sha256:aaa", want: service.BundleMatch},
+		{ok: true, id: "sha256:bbb", want: service.BundleMismatch},
+	}
+	for _, tc := range cases {
+		if got := service.CompareBundle(recorded, tc.ok, tc.id); got != tc.want {
+			t.Fatalf("CompareBundle(%v, %q) = %q, want %q", tc.ok, tc.id, got, tc.want)
+		}
+	}
+}
+
+func bundleTar(t *testing.T, entries map[string]string, names ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		data := entries[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestLoadChecksBundledConfig(t *testing.T) {
+	const manifest = `{"version":1,"images":[{"alias":"code","ref":"cradle/code:latest","image_id":"sha256:aaa"}]}`
+	cases := []struct {
+		name    string
+		config  string
+		entries []string
+		want    string
+	}{
+		{
+			name:    "missing",
+			entries: []string{"manifest.json", "images.tar"},
+			want:    "missing config.yaml",
+		},
+		{
+			name:    "invalid",
+			config:  "version: 1\naliases:\n  code: {}\n",
+			entries: []string{"manifest.json", "config.yaml", "images.tar"},
+			want:    "config.yaml",
+		},
+		{
+			name:    "alias not defined",
+			config:  "version: 1\naliases:\n  web:\n    image:\n      pull:\n        ref: nginx:1.27\n",
+			entries: []string{"manifest.json", "config.yaml", "images.tar"},
+			want:    `no alias "code"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := bundleTar(t, map[string]string{
+				"manifest.json": manifest,
+				"config.yaml":   tc.config,
+				"images.tar":    "",
+			}, tc.entries...)
+			svc := service.NewWithClient(&config.Config{}, nil)
+			_, err := svc.Load(context.Background(), bundle, io.Discard)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	source := t.TempDir()
+	create := false
+	cfg := &config.Config{
+		Version: 1,
+		Aliases: map[string]config.Alias{
+			"code": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}},
+				Run: config.RunSpec{
+					MountCwd: &config.MountCwdSpec{Enabled: false},
+					Volumes: []config.MountSpec{
+						{Type: "bind", Source: source, Target: "/data", Create: &create},
+					},
+				},
+			},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	svc := newFakeDaemonService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			_, _ = io.WriteString(w, `{"Id":"sha256:aaa"}`)
+		case strings.HasSuffix(r.URL.Path, "/images/get"):
+			_, _ = io.WriteString(w, "images")
+		case strings.HasSuffix(r.URL.Path, "/images/load"):
+			_, _ = io.WriteString(w, `{"stream":"Loaded image ID: sha256:aaa\n"}`)
+		case strings.HasSuffix(r.URL.Path, "/tag"):
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}), cfg)
+
+	var bundle bytes.Buffer
+	if _, err := svc.Save(context.Background(), []string{"code"}, &bundle); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// The bind source only exists on the machine that saved the bundle.
+	if err := os.Remove(source); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := svc.Load(context.Background(), &bundle, io.Discard)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	loaded, err := config.ParsePortable(manifest.Config)
+	if err != nil {
+		t.Fatalf("ParsePortable: %v", err)
+	}
+	if mount := loaded.Aliases["code"].Run.MountCwd; mount == nil || mount.Enabled {
+		t.Fatalf("expected mount_cwd to stay disabled, got %+v", mount)
+	}
+}
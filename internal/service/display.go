@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+const (
+	x11SocketDir        = "/tmp/.X11-unix"
+	displayXauthTarget  = forwardTargetDir + "/Xauthority"
+	displayRuntimeDir   = forwardTargetDir + "/xdg"
+	defaultWaylandName  = "wayland-0"
+	xauthFamilyLocal    = 256
+	xauthFamilyWild     = 0xffff
+	xauthFileMode       = 0o600
+	xauthCountedStrings = 4
+)
+
+// DisplaySession holds the env entries and mounts that expose the host display.
+type DisplaySession struct {
+	Env    map[string]string
+	Mounts []config.MountSpec
+
+	// XauthSource is the host Xauthority file and XDisplayNumber the display it is scoped to.
+	XauthSource    string
+	XDisplayNumber string
+}
+
+// ResolveDisplay inspects the host session for the requested display mode.
+// The returned error explains what is missing when no display is available.
+func ResolveDisplay(mode config.DisplayMode) (DisplaySession, error) {
+	switch mode {
+	case "":
+		return DisplaySession{}, nil
+	case config.DisplayX11:
+		return resolveX11()
+	case config.DisplayWayland:
+		return resolveWayland()
+	case config.DisplayAuto:
+		wayland, waylandErr := resolveWayland()
+		x11, x11Err := resolveX11()
+		if waylandErr != nil && x11Err != nil {
+			return DisplaySession{}, fmt.Errorf("no display available: %w", errors.Join(waylandErr, x11Err))
+		}
+		return mergeDisplaySessions(wayland, x11), nil
+	default:
+		return DisplaySession{}, fmt.Errorf("unknown display mode %q", mode)
+	}
+}
+
+// WithXauthority writes a cookie file scoped to the forwarded X11 display at path
+// and adds it to the session. Sessions without an Xauthority source are returned as is.
+func (d DisplaySession) WithXauthority(path string) (DisplaySession, error) {
+	if d.XauthSource == "" {
+		return d, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), forwardDirMode); err != nil {
+		return d, err
+	}
+	if err := WriteScopedXauthority(d.XauthSource, d.XDisplayNumber, path); err != nil {
+		if errors.Is(err, ErrNoXauthCookie) {
+			return d, nil
+		}
+		return d, fmt.Errorf("xauthority: %w", err)
+	}
+	d.Env = maps.Clone(d.Env)
+	d.Env["XAUTHORITY"] = displayXauthTarget
+	d.Mounts = append(d.Mounts, config.MountSpec{
+		Type:     "bind",
+		Source:   path,
+		Target:   displayXauthTarget,
+		ReadOnly: true,
+	})
+	return d, nil
+}
+
+// ApplyDisplay adds the session env and mounts to run.
+func ApplyDisplay(run config.RunSpec, d DisplaySession) config.RunSpec {
+	if len(d.Env) == 0 && len(d.Mounts) == 0 {
+		return run
+	}
+	env := maps.Clone(run.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, d.Env)
+	run.Env = env
+	run.Volumes = append(append([]config.MountSpec{}, run.Volumes...), d.Mounts...)
+	return run
+}
+
+// CheckDisplays reports aliases whose run.display is not available in the host session.
+func (s *Service) CheckDisplays() []error {
+	names := make([]string, 0, len(s.cfg.Aliases))
+	for name := range s.cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []error
+	for _, name := range names {
+		mode := s.cfg.Aliases[name].Run.Display
+		if mode == "" {
+			continue
+		}
+		if _, err := ResolveDisplay(mode); err != nil {
+			problems = append(problems, fmt.Errorf("aliases.%s.run.display: %w", name, err))
+		}
+	}
+	return problems
+}
+
+// DisplayXauthPath returns the host path of the generated cookie file for a container.
+func DisplayXauthPath(containerName string) string {
+	return filepath.Join(filepath.Dir(ForwardDir()), "display", containerName+".xauth")
+}
+
+func resolveX11() (DisplaySession, error) {
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return DisplaySession{}, errors.New("x11: DISPLAY is not set")
+	}
+
+	sep := strings.LastIndex(display, ":")
+	if sep == -1 {
+		return DisplaySession{}, fmt.Errorf("x11: invalid DISPLAY %q", display)
+	}
+	host := display[:sep]
+	number, _, _ := strings.Cut(display[sep+1:], ".")
+	if number == "" {
+		return DisplaySession{}, fmt.Errorf("x11: invalid DISPLAY %q", display)
+	}
+
+	session := DisplaySession{Env: map[string]string{"DISPLAY": display}, XDisplayNumber: number}
+	if host == "" || host == "unix" {
+		socket := filepath.Join(x11SocketDir, "X"+number)
+		if !fileExists(socket) {
+			return DisplaySession{}, fmt.Errorf("x11: socket %s for DISPLAY %q not found", socket, display)
+		}
+		session.Env["DISPLAY"] = display[sep:]
+		session.Mounts = append(session.Mounts, config.MountSpec{
+			Type:   "bind",
+			Source: x11SocketDir,
+			Target: x11SocketDir,
+		})
+	}
+
+	if xauth := hostXauthority(); xauth != "" {
+		session.XauthSource = xauth
+	}
+	return session, nil
+}
+
+func resolveWayland() (DisplaySession, error) {
+	name := os.Getenv("WAYLAND_DISPLAY")
+	if name == "" {
+		return DisplaySession{}, errors.New("wayland: WAYLAND_DISPLAY is not set")
+	}
+	socket := name
+	if !filepath.IsAbs(socket) {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return DisplaySession{}, errors.New("wayland: XDG_RUNTIME_DIR is not set")
+		}
+		socket = filepath.Join(runtimeDir, name)
+	}
+	if !fileExists(socket) {
+		return DisplaySession{}, fmt.Errorf("wayland: socket %s not found", socket)
+	}
+
+	target := defaultWaylandName
+	if !filepath.IsAbs(name) {
+		target = name
+	}
+	return DisplaySession{
+		Env: map[string]string{
+			"WAYLAND_DISPLAY": target,
+			"XDG_RUNTIME_DIR": displayRuntimeDir,
+		},
+		Mounts: []config.MountSpec{{
+			Type:   "bind",
+			Source: socket,
+			Target: displayRuntimeDir + "/" + target,
+		}},
+	}, nil
+}
+
+func mergeDisplaySessions(wayland, x11 DisplaySession) DisplaySession {
+	merged := DisplaySession{
+		Env:            map[string]string{},
+		Mounts:         append(append([]config.MountSpec{}, wayland.Mounts...), x11.Mounts...),
+		XauthSource:    x11.XauthSource,
+		XDisplayNumber: x11.XDisplayNumber,
+	}
+	maps.Copy(merged.Env, wayland.Env)
+	maps.Copy(merged.Env, x11.Env)
+	return merged
+}
+
+func hostXauthority() string {
+	if path := os.Getenv("XAUTHORITY"); path != "" && fileExists(path) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if path := filepath.Join(home, ".Xauthority"); fileExists(path) {
+		return path
+	}
+	return ""
+}
+
+// ErrNoXauthCookie reports that the Xauthority file has no cookie for the display.
+var ErrNoXauthCookie = errors.New("no xauthority cookie for display")
+
+type xauthEntry struct {
+	family uint16
+	fields [xauthCountedStrings][]byte // address, display number, auth name, auth data
+}
+
+// WriteScopedXauthority copies the cookies for display number from src into dst.
+// Entries are rewritten to the wildcard family so they match the container hostname.
+func WriteScopedXauthority(src, number, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	entries, err := parseXauthority(data)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	for _, entry := range entries {
+		if string(entry.fields[1]) != number {
+			continue
+		}
+		if entry.family != xauthFamilyLocal && entry.family != xauthFamilyWild {
+			continue
+		}
+		entry.family = xauthFamilyWild
+		writeXauthEntry(&out, entry)
+	}
+	if out.Len() == 0 {
+		return fmt.Errorf("%w :%s in %s", ErrNoXauthCookie, number, src)
+	}
+
+	// Write in place so running containers that bind-mount dst see the new cookie.
+	return os.WriteFile(dst, out.Bytes(), xauthFileMode)
+}
+
+func parseXauthority(data []byte) ([]xauthEntry, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+	var entries []xauthEntry
+	for {
+		var entry xauthEntry
+		if err := binary.Read(r, binary.BigEndian, &entry.family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("parse xauthority: %w", err)
+		}
+		for i := range entry.fields {
+			var n uint16
+			if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+				return nil, fmt.Errorf("parse xauthority: %w", err)
+			}
+			entry.fields[i] = make([]byte, n)
+			if _, err := io.ReadFull(r, entry.fields[i]); err != nil {
+				return nil, fmt.Errorf("parse xauthority: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func writeXauthEntry(out *bytes.Buffer, entry xauthEntry) {
+	_ = binary.Write(out, binary.BigEndian, entry.family)
+	for _, field := range entry.fields {
+		_ = binary.Write(out, binary.BigEndian, uint16(len(field))) //nolint:gosec // fields were read with a uint16 length
+		out.Write(field)
+	}
+}
@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func xauthRecord(family uint16, fields ...string) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, family)
+	for _, field := range fields {
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(field)))
+		buf.WriteString(field)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteScopedXauthority(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "Xauthority")
+	dst := filepath.Join(dir, "scoped.xauth")
+
+	var data []byte
+	data = append(data, xauthRecord(256, "host", "0", "MIT-MAGIC-COOKIE-1", "cookie0")...)
+	data = append(data, xauthRecord(256, "host", "1", "MIT-MAGIC-COOKIE-1", "cookie1")...)
+	data = append(data, xauthRecord(0, "\x7f\x00\x00\x01", "0", "MIT-MAGIC-COOKIE-1", "tcp")...)
+	if err := os.WriteFile(src, data, 0o600); err != nil {
+		t.Fatalf("write xauthority: %v", err)
+	}
+
+	if err := service.WriteScopedXauthority(src, "0", dst); err != nil {
+		t.Fatalf("WriteScopedXauthority error: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("read scoped: %v", err)
+	}
+	want := xauthRecord(0xffff, "host", "0", "MIT-MAGIC-COOKIE-1", "cookie0")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("unexpected scoped xauthority: %q", got)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("stat scoped: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected mode: %v", info.Mode().Perm())
+	}
+
+	if err = service.WriteScopedXauthority(src, "7", dst); err == nil {
+		t.Fatalf("expected error for display without cookie")
+	}
+}
+
+func TestResolveDisplayMissing(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	for _, mode := range []config.DisplayMode{config.DisplayX11, config.DisplayWayland, config.DisplayAuto} {
+		if _, err := service.ResolveDisplay(mode); err == nil {
+			t.Fatalf("expected error for %s without a session", mode)
+		}
+	}
+
+	_, err := service.ResolveDisplay(config.DisplayAuto)
+	if !strings.Contains(err.Error(), "DISPLAY is not set") || !strings.Contains(err.Error(), "WAYLAND_DISPLAY") {
+		t.Fatalf("expected auto error to explain both backends, got %v", err)
+	}
+}
+
+func TestResolveDisplayWayland(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-1")
+	t.Setenv("DISPLAY", "")
+	socket := filepath.Join(runtimeDir, "wayland-1")
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatalf("write socket stand-in: %v", err)
+	}
+
+	session, err := service.ResolveDisplay(config.DisplayAuto)
+	if err != nil {
+		t.Fatalf("ResolveDisplay error: %v", err)
+	}
+	if session.Env["WAYLAND_DISPLAY"] != "wayland-1" || session.Env["XDG_RUNTIME_DIR"] != "/run/cradle/xdg" {
+		t.Fatalf("unexpected env: %v", session.Env)
+	}
+	if len(session.Mounts) != 1 || session.Mounts[0].Source != socket ||
+		session.Mounts[0].Target != "/run/cradle/xdg/wayland-1" {
+		t.Fatalf("unexpected mounts: %+v", session.Mounts)
+	}
+}
+
+func TestApplyDisplay(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "Xauthority")
+	if err := os.WriteFile(src, xauthRecord(256, "host", "3", "MIT-MAGIC-COOKIE-1", "c"), 0o600); err != nil {
+		t.Fatalf("write xauthority: %v", err)
+	}
+
+	session := service.DisplaySession{
+		Env:            map[string]string{"DISPLAY": ":3"},
+		XauthSource:    src,
+		XDisplayNumber: "3",
+	}
+	session, err := session.WithXauthority(filepath.Join(dir, "out", "demo.xauth"))
+	if err != nil {
+		t.Fatalf("WithXauthority error: %v", err)
+	}
+
+	run := config.RunSpec{
+		Env:     map[string]string{"FOO": "bar"},
+		Volumes: []config.MountSpec{{Type: "volume", Source: "data", Target: "/data"}},
+	}
+	got := service.ApplyDisplay(run, session)
+	if got.Env["FOO"] != "bar" || got.Env["DISPLAY"] != ":3" || got.Env["XAUTHORITY"] != "/run/cradle/Xauthority" {
+		t.Fatalf("unexpected env: %v", got.Env)
+	}
+	if len(got.Volumes) != 2 || got.Volumes[1].Target != "/run/cradle/Xauthority" || !got.Volumes[1].ReadOnly {
+		t.Fatalf("unexpected volumes: %+v", got.Volumes)
+	}
+	if len(run.Volumes) != 1 || len(run.Env) != 1 {
+		t.Fatalf("expected input run to be left untouched")
+	}
+}
+
+func TestCheckDisplays(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"gui":   {Run: config.RunSpec{Display: config.DisplayX11}},
+			"shell": {},
+		},
+	}
+	problems := service.NewWithClient(cfg, nil).CheckDisplays()
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "aliases.gui.run.display") {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+}
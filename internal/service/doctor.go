@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/api/types/build"
+	"github.com/moby/moby/api/types/system"
+	"github.com/moby/moby/client"
+	"github.com/moby/moby/client/pkg/versions"
+)
+
+// CheckStatus is the outcome of a doctor check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+const (
+	nvidiaRuntime = "nvidia"
+	cdiDriver     = "cdi"
+)
+
+// Check is one line of the doctor report. Fix suggests what to do when the check
+// did not pass.
+type Check struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail"`
+	Fix    string      `json:"fix,omitempty"`
+}
+
+func passCheck(name, detail string) Check {
+	return Check{Name: name, Status: CheckPass, Detail: detail}
+}
+
+func warnCheck(name, detail, fix string) Check {
+	return Check{Name: name, Status: CheckWarn, Detail: detail, Fix: fix}
+}
+
+func failCheck(name, detail, fix string) Check {
+	return Check{Name: name, Status: CheckFail, Detail: detail, Fix: fix}
+}
+
+// FailedChecks counts the checks that failed.
+func FailedChecks(checks []Check) int {
+	failed := 0
+	for _, check := range checks {
+		if check.Status == CheckFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// CheckConfig loads the config at path. It returns the loaded config, or nil when
+// the file is missing or invalid, with the check describing why.
+func CheckConfig(path string) (*config.Config, Check) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, failCheck("config", path+" does not exist",
+			"create it (see docs/CONFIG.md) or pass --config <path>")
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, failCheck("config", err.Error(), "fix the reported field and run `cradle config validate`")
+	}
+	return cfg, passCheck("config", fmt.Sprintf("%s (%d aliases)", path, len(cfg.Aliases)))
+}
+
+// Doctor checks the engine, the directories cradle writes to and the host
+// requirements of the configured aliases.
+func (s *Service) Doctor(ctx context.Context) []Check {
+	checks := s.engineChecks(ctx)
+	checks = append(checks, DirChecks()...)
+	return append(checks, s.bindSourceChecks()...)
+}
+
+// engineChecks pings the daemon and, when it answers, checks its API version,
+// BuildKit support and the runtimes and GPU drivers aliases need.
+func (s *Service) engineChecks(ctx context.Context) []Check {
+	ping, err := s.cli.Ping(ctx, client.PingOptions{NegotiateAPIVersion: true})
+	if err != nil {
+		return []Check{failCheck("engine", err.Error(),
+			"start the Docker daemon, or point DOCKER_HOST or the Docker context at a reachable one")}
+	}
+	checks := []Check{passCheck("engine", "reachable at "+s.cli.DaemonHost()), apiVersionCheck(ping.APIVersion)}
+	checks = append(checks, s.buildkitChecks(ctx, ping.BuilderVersion)...)
+
+	info, err := s.cli.Info(ctx, client.InfoOptions{})
+	if err != nil {
+		return append(checks, warnCheck("runtimes", "cannot read daemon info: "+err.Error(), ""))
+	}
+	checks = append(checks, s.runtimeChecks(info.Info)...)
+	return append(checks, s.gpuChecks(info.Info)...)
+}
+
+func apiVersionCheck(apiVersion string) Check {
+	if apiVersion != "" && versions.LessThan(apiVersion, client.MinAPIVersion) {
+		return failCheck("api version",
+			fmt.Sprintf("daemon API %s is older than the minimum %s", apiVersion, client.MinAPIVersion),
+			"upgrade Docker Engine")
+	}
+	return passCheck("api version", fmt.Sprintf("daemon API %s (client supports %s to %s)",
+		apiVersion, client.MinAPIVersion, client.MaxAPIVersion))
+}
+
+// buildkitChecks reports the default builder and whether the daemon accepts the
+// session BuildKit builds need. Without one, builds fall back to the v1 builder.
+func (s *Service) buildkitChecks(ctx context.Context, builder build.BuilderVersion) []Check {
+	const fix = "enable BuildKit: upgrade to Docker Engine 23+ or set \"features\": {\"buildkit\": true} " +
+		"in /etc/docker/daemon.json"
+	var checks []Check
+	if builder == build.BuilderBuildKit {
+		checks = append(checks, passCheck("buildkit", "default builder is BuildKit"))
+	} else {
+		checks = append(checks, warnCheck("buildkit", "default builder is the classic builder", fix))
+	}
+	conn, err := s.cli.DialHijack(ctx, "/session", "h2c", map[string][]string{
+		"X-Docker-Expose-Session-Uuid": {"cradle-doctor"},
+		"X-Docker-Expose-Session-Name": {"cradle-doctor"},
+	})
+	if err != nil {
+		return append(checks, warnCheck("buildkit session",
+			"session endpoint unavailable, builds fall back to the v1 builder: "+err.Error(), fix))
+	}
+	_ = conn.Close()
+	return append(checks, passCheck("buildkit session", "session endpoint accepts connections"))
+}
+
+// runtimeChecks checks that every run.runtime is registered with the daemon.
+func (s *Service) runtimeChecks(info system.Info) []Check {
+	var checks []Check
+	for _, name := range s.aliasNames() {
+		runtime := s.cfg.Aliases[name].Run.Runtime
+		if runtime == "" {
+			continue
+		}
+		check := "aliases." + name + ".run.runtime"
+		if _, ok := info.Runtimes[runtime]; ok {
+			checks = append(checks, passCheck(check, runtime+" is registered"))
+			continue
+		}
+		checks = append(checks, failCheck(check,
+			fmt.Sprintf("%s is not registered (available: %s)", runtime, strings.Join(runtimeNames(info), ", ")),
+			"install "+runtime+" and register it under \"runtimes\" in /etc/docker/daemon.json"))
+	}
+	return checks
+}
+
+func runtimeNames(info system.Info) []string {
+	names := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// gpuChecks looks for a way to serve each run.gpus driver. The daemon does not
+// report device drivers, so the NVIDIA runtime and CDI spec directories stand in
+// for them.
+func (s *Service) gpuChecks(info system.Info) []Check {
+	var checks []Check
+	for _, name := range s.aliasNames() {
+		for i, gpu := range s.cfg.Aliases[name].Run.GPUs {
+			checks = append(checks, gpuCheck(fmt.Sprintf("aliases.%s.run.gpus[%d]", name, i), gpu.Driver, info))
+		}
+	}
+	return checks
+}
+
+func gpuCheck(check, driver string, info system.Info) Check {
+	_, hasNvidia := info.Runtimes[nvidiaRuntime]
+	hasCDI := len(info.CDISpecDirs) > 0
+	switch {
+	case driver == cdiDriver && hasCDI:
+		return passCheck(check, "CDI spec directories: "+strings.Join(info.CDISpecDirs, ", "))
+	case driver == cdiDriver:
+		return warnCheck(check, "the daemon has no CDI spec directories",
+			"enable CDI in /etc/docker/daemon.json and generate specs for your devices")
+	case driver != "" && driver != nvidiaRuntime:
+		return warnCheck(check, "cannot check driver "+driver, "")
+	case hasNvidia || hasCDI:
+		return passCheck(check, "NVIDIA runtime or CDI specs found")
+	default:
+		return warnCheck(check, "no NVIDIA runtime or CDI spec directories",
+			"install the NVIDIA Container Toolkit and run `nvidia-ctk runtime configure --runtime=docker`")
+	}
+}
+
+// DirChecks checks that the state, cache and runtime directories cradle uses are
+// writable, or can be created.
+func DirChecks() []Check {
+	return []Check{
+		dirCheck("state dir", stateDir(""), "XDG_STATE_HOME"),
+		dirCheck("cache dir", cacheDir(""), "XDG_CACHE_HOME"),
+		dirCheck("runtime dir", filepath.Dir(ForwardDir()), "XDG_RUNTIME_DIR"),
+	}
+}
+
+func dirCheck(name, dir, env string) Check {
+	fix := "make it writable or set " + env + " to a writable directory"
+	existing := dir
+	for {
+		st, err := os.Stat(existing)
+		if err == nil {
+			if !st.IsDir() {
+				return failCheck(name, existing+" is not a directory", fix)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if !errors.Is(err, os.ErrNotExist) || parent == existing {
+			return failCheck(name, err.Error(), fix)
+		}
+		existing = parent
+	}
+	f, err := os.CreateTemp(existing, ".cradle-doctor-*")
+	if err != nil {
+		return failCheck(name, existing+" is not writable", fix)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	if existing != dir {
+		return passCheck(name, dir+" (created on first use)")
+	}
+	return passCheck(name, dir)
+}
+
+// bindSourceChecks reports bind mount sources that do not exist. Sources with
+// create: true are made before run; others are left to Docker, which creates an
+// empty root-owned directory.
+func (s *Service) bindSourceChecks() []Check {
+	var checks []Check
+	for _, name := range s.aliasNames() {
+		found := 0
+		var missing []Check
+		for i, m := range s.cfg.Aliases[name].Run.Volumes {
+			if m.Type != "bind" || m.Source == "" {
+				continue
+			}
+			if _, err := os.Stat(m.Source); err == nil {
+				found++
+				continue
+			}
+			check := fmt.Sprintf("aliases.%s.run.volumes[%d]", name, i)
+			if m.Create != nil && *m.Create {
+				missing = append(missing, passCheck(check, m.Source+" is created on run"))
+				continue
+			}
+			missing = append(missing, warnCheck(check, m.Source+" does not exist",
+				"create it, or set create: true to have cradle create it"))
+		}
+		if found > 0 {
+			checks = append(checks, passCheck("aliases."+name+" bind sources", fmt.Sprintf("%d found", found)))
+		}
+		checks = append(checks, missing...)
+	}
+	return checks
+}
+
+func (s *Service) aliasNames() []string {
+	if s.cfg == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.cfg.Aliases))
+	for name := range s.cfg.Aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
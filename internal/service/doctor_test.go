@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+// fakeEngine answers ping and info like a daemon with the classic builder, only
+// the runc runtime and no session endpoint.
+func fakeEngine(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/_ping"):
+		w.Header().Set("Api-Version", "1.52")
+		w.Header().Set("Builder-Version", "1")
+		_, _ = w.Write([]byte("OK"))
+	case strings.HasSuffix(r.URL.Path, "/info"):
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Runtimes":{"runc":{"path":"runc"}}}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// dropConnection closes every connection without a response, like a daemon that
+// is not listening.
+func dropConnection(w http.ResponseWriter, _ *http.Request) {
+	if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+		_ = conn.Close()
+	}
+}
+
+func doctorChecks(t *testing.T, cfg *config.Config) map[string]service.Check {
+	t.Helper()
+	svc := newFakeDaemonService(t, http.HandlerFunc(fakeEngine), cfg)
+	checks := map[string]service.Check{}
+	for _, check := range svc.Doctor(context.Background()) {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestDoctorReportsEngineAndAliasRequirements(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	existing := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+	create := true
+	checks := doctorChecks(t, &config.Config{Aliases: map[string]config.Alias{
+		"gpu": {Run: config.RunSpec{
+			Runtime: "nvidia",
+			GPUs:    []config.GPURequestSpec{{Count: config.DeviceCountAll}},
+			Volumes: []config.MountSpec{
+				{Type: "bind", Source: existing, Target: "/src"},
+				{Type: "bind", Source: missing, Target: "/data"},
+				{Type: "bind", Source: missing, Target: "/made", Create: &create},
+				{Type: "volume", Source: "cache", Target: "/cache"},
+			},
+		}},
+	}})
+
+	for name, want := range map[string]service.CheckStatus{
+		"engine":                     service.CheckPass,
+		"api version":                service.CheckPass,
+		"buildkit":                   service.CheckWarn,
+		"buildkit session":           service.CheckWarn,
+		"aliases.gpu.run.runtime":    service.CheckFail,
+		"aliases.gpu.run.gpus[0]":    service.CheckWarn,
+		"state dir":                  service.CheckPass,
+		"cache dir":                  service.CheckPass,
+		"runtime dir":                service.CheckPass,
+		"aliases.gpu bind sources":   service.CheckPass,
+		"aliases.gpu.run.volumes[1]": service.CheckWarn,
+		"aliases.gpu.run.volumes[2]": service.CheckPass,
+	} {
+		check, ok := checks[name]
+		if !ok || check.Status != want {
+			t.Fatalf("check %q: got %+v, want %s", name, check, want)
+		}
+		if want != service.CheckPass && check.Fix == "" && name != "buildkit session" {
+			t.Fatalf("check %q has no suggested fix", name)
+		}
+	}
+	if !strings.Contains(checks["aliases.gpu.run.runtime"].Detail, "available: runc") {
+		t.Fatalf("expected available runtimes, got %q", checks["aliases.gpu.run.runtime"].Detail)
+	}
+	if !strings.Contains(checks["state dir"].Detail, "created on first use") {
+		t.Fatalf("expected missing state dir to be created later, got %q", checks["state dir"].Detail)
+	}
+}
+
+func TestDoctorEngineUnreachable(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	svc := newFakeDaemonService(t, http.HandlerFunc(dropConnection), &config.Config{})
+
+	checks := svc.Doctor(context.Background())
+	if checks[0].Name != "engine" || checks[0].Status != service.CheckFail || checks[0].Fix == "" {
+		t.Fatalf("expected failing engine check, got %+v", checks[0])
+	}
+	if service.FailedChecks(checks) != 1 {
+		t.Fatalf("expected only the engine check to fail, got %+v", checks)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, check := service.CheckConfig(filepath.Join(dir, "missing.yaml")); cfg != nil ||
+		check.Status != service.CheckFail || !strings.Contains(check.Detail, "does not exist") {
+		t.Fatalf("expected missing config to fail, got %+v", check)
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	valid := "aliases:\n  base:\n    image:\n      pull:\n        ref: ubuntu:24.04\n"
+	if err := os.WriteFile(path, []byte(valid), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, check := service.CheckConfig(path)
+	if cfg == nil || check.Status != service.CheckPass || !strings.Contains(check.Detail, "(1 aliases)") {
+		t.Fatalf("expected valid config, got %+v", check)
+	}
+
+	if err := os.WriteFile(path, []byte("aliases:\n  base: {}\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if cfg, check = service.CheckConfig(path); cfg != nil || check.Status != service.CheckFail {
+		t.Fatalf("expected invalid config to fail, got %+v", check)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rhajizada/cradle/internal/termutil"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+	"golang.org/x/term"
+)
+
+var defaultExecCmd = []string{"/bin/sh"}
+
+const (
+	execPollInitial = 10 * time.Millisecond
+	execPollMax     = 500 * time.Millisecond
+)
+
+// ExecOptions configures a command run inside an existing container.
+type ExecOptions struct {
+	Cmd    []string
+	Stdin  *os.File
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Exec runs opts.Cmd (default /bin/sh) in the running target container and returns
+// its exit code. A TTY is allocated when stdin is a terminal. For host_user aliases
+// the command runs as the caller.
+func (s *Service) Exec(ctx context.Context, target Target, here bool, opts ExecOptions) (int, error) {
+	ctr, run, err := s.inspectTarget(ctx, target, here)
+	if err != nil {
+		return 0, err
+	}
+	if ctr.Container.State == nil || !ctr.Container.State.Running {
+		return 0, fmt.Errorf("container for %s is not running", target)
+	}
+
+	cmd := opts.Cmd
+	if len(cmd) == 0 {
+		cmd = defaultExecCmd
+	}
+	tty := false
+	if opts.Stdin != nil {
+		if fd, ok := termutil.Int(opts.Stdin.Fd()); ok {
+			tty = term.IsTerminal(fd)
+		}
+	}
+
+	createOpts := client.ExecCreateOptions{
+		TTY:          tty,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	}
+	if run.HostUser {
+		identity, identityErr := CurrentHostIdentity()
+		if identityErr != nil {
+			return 0, fmt.Errorf("resolve host user: %w", identityErr)
+		}
+		if identity.UID != "" && identity.UID != "0" {
+			createOpts.User = identity.UID + ":" + identity.GID
+			createOpts.Env = []string{"HOME=" + identity.Home, "USER=" + identity.Username}
+		}
+	}
+
+	created, err := s.cli.ExecCreate(ctx, ctr.Container.ID, createOpts)
+	if err != nil {
+		return 0, err
+	}
+	attached, err := s.cli.ExecAttach(ctx, created.ID, client.ExecAttachOptions{TTY: tty})
+	if err != nil {
+		return 0, err
+	}
+	defer attached.Close()
+
+	restore := attachTerminal(opts.Stdin, tty, func(width, height uint) {
+		_, _ = s.cli.ExecResize(context.Background(), created.ID, client.ExecResizeOptions{
+			Width:  width,
+			Height: height,
+		})
+	})
+	defer restore()
+
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(attached.Conn, opts.Stdin)
+			_ = attached.CloseWrite()
+		}()
+	}
+	streamDone := make(chan struct{})
+	go func() {
+		if tty {
+			_, _ = io.Copy(opts.Stdout, attached.Reader)
+		} else {
+			_, _ = stdcopy.StdCopy(opts.Stdout, opts.Stderr, attached.Reader)
+		}
+		close(streamDone)
+	}()
+	select {
+	case <-streamDone:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	return s.waitExec(ctx, created.ID)
+}
+
+// waitExec polls the exec until the daemon reports it stopped. The output stream
+// can close before the process is reaped, and the exit code is only final then.
+func (s *Service) waitExec(ctx context.Context, id string) (int, error) {
+	delay := execPollInitial
+	for {
+		inspect, err := s.cli.ExecInspect(ctx, id, client.ExecInspectOptions{})
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, execPollMax)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/api/types/mount"
+)
+
+const (
+	forwardTargetDir      = "/run/cradle"
+	forwardSSHSocket      = "ssh-agent.sock"
+	forwardGPGSocket      = "gpg-agent.sock"
+	forwardGnuPGHome      = forwardTargetDir + "/gnupg"
+	forwardGitConfig      = forwardTargetDir + "/gitconfig"
+	forwardGitCredentials = forwardTargetDir + "/git-credentials"
+	forwardDirMode        = 0o700
+)
+
+// Forwards holds the mounts and environment entries for run.forward.
+type Forwards struct {
+	Mounts []mount.Mount
+	Env    []string
+}
+
+// ForwardDir returns the per-user host directory that holds stable links to agent sockets.
+// Containers bind-mount these links, so a socket path that changes between sessions
+// is picked up the next time the container starts without changing its fingerprint.
+func ForwardDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "cradle", "forward")
+	}
+	return filepath.Join(os.TempDir(), "cradle-"+strconv.Itoa(os.Getuid()), "forward")
+}
+
+// PrepareForwards points the stable socket links in ForwardDir at the current host agents.
+func PrepareForwards(ctx context.Context, kinds []config.ForwardKind) error {
+	for _, kind := range kinds {
+		var socket string
+		var err error
+		switch kind {
+		case config.ForwardSSHAgent:
+			socket, err = hostSSHAgentSocket()
+		case config.ForwardGPGAgent:
+			socket, err = hostGPGAgentSocket(ctx)
+		case config.ForwardGitConfig:
+			continue
+		default:
+			return fmt.Errorf("unknown forward %q", kind)
+		}
+		if err != nil {
+			return err
+		}
+		if linkErr := refreshLink(socket, filepath.Join(ForwardDir(), forwardSocketName(kind))); linkErr != nil {
+			return fmt.Errorf("forward %s: %w", kind, linkErr)
+		}
+	}
+	return nil
+}
+
+// ResolveForwards returns the mounts and environment for the requested forwards.
+func ResolveForwards(kinds []config.ForwardKind) (Forwards, error) {
+	var fwd Forwards
+	for _, kind := range kinds {
+		switch kind {
+		case config.ForwardSSHAgent:
+			target := forwardTargetDir + "/" + forwardSSHSocket
+			fwd.Mounts = append(fwd.Mounts, bindMount(filepath.Join(ForwardDir(), forwardSSHSocket), target, false))
+			fwd.Env = append(fwd.Env, "SSH_AUTH_SOCK="+target)
+		case config.ForwardGPGAgent:
+			fwd.addGPG()
+		case config.ForwardGitConfig:
+			if err := fwd.addGitConfig(); err != nil {
+				return Forwards{}, err
+			}
+		default:
+			return Forwards{}, fmt.Errorf("unknown forward %q", kind)
+		}
+	}
+	return fwd, nil
+}
+
+func (f *Forwards) addGPG() {
+	f.Mounts = append(f.Mounts, bindMount(
+		filepath.Join(ForwardDir(), forwardGPGSocket),
+		forwardGnuPGHome+"/S.gpg-agent",
+		false,
+	))
+	home := hostGnuPGHome()
+	for _, name := range []string{"pubring.kbx", "trustdb.gpg"} {
+		source := filepath.Join(home, name)
+		if fileExists(source) {
+			f.Mounts = append(f.Mounts, bindMount(source, forwardGnuPGHome+"/"+name, true))
+		}
+	}
+	f.Env = append(f.Env, "GNUPGHOME="+forwardGnuPGHome)
+}
+
+func (f *Forwards) addGitConfig() error {
+	source := hostGitConfig()
+	if source == "" {
+		return errors.New("forward gitconfig: no global git config found")
+	}
+	f.Mounts = append(f.Mounts, bindMount(source, forwardGitConfig, true))
+	f.Env = append(f.Env, "GIT_CONFIG_GLOBAL="+forwardGitConfig)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil //nolint:nilerr // credentials are optional
+	}
+	credentials := filepath.Join(home, ".git-credentials")
+	if !fileExists(credentials) {
+		return nil
+	}
+	f.Mounts = append(f.Mounts, bindMount(credentials, forwardGitCredentials, true))
+	f.Env = append(f.Env,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=credential.helper",
+		"GIT_CONFIG_VALUE_0=store --file="+forwardGitCredentials,
+	)
+	return nil
+}
+
+func forwardSocketName(kind config.ForwardKind) string {
+	if kind == config.ForwardGPGAgent {
+		return forwardGPGSocket
+	}
+	return forwardSSHSocket
+}
+
+func hostSSHAgentSocket() (string, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return "", errors.New("forward ssh-agent: SSH_AUTH_SOCK is not set")
+	}
+	if !fileExists(socket) {
+		return "", fmt.Errorf("forward ssh-agent: socket %q not found", socket)
+	}
+	return socket, nil
+}
+
+func hostGPGAgentSocket(ctx context.Context) (string, error) {
+	for _, dir := range []string{"agent-extra-socket", "agent-socket"} {
+		out, err := exec.CommandContext(ctx, "gpgconf", "--list-dirs", dir).Output()
+		if err != nil {
+			break
+		}
+		if socket := strings.TrimSpace(string(out)); socket != "" && fileExists(socket) {
+			return socket, nil
+		}
+	}
+	home := hostGnuPGHome()
+	for _, name := range []string{"S.gpg-agent.extra", "S.gpg-agent"} {
+		if socket := filepath.Join(home, name); fileExists(socket) {
+			return socket, nil
+		}
+	}
+	return "", errors.New("forward gpg-agent: no gpg-agent socket found")
+}
+
+func hostGnuPGHome() string {
+	if home := os.Getenv("GNUPGHOME"); home != "" {
+		return home
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gnupg")
+}
+
+func hostGitConfig() string {
+	candidates := []string{os.Getenv("GIT_CONFIG_GLOBAL")}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".gitconfig"))
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "git", "config"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".config", "git", "config"))
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && fileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func refreshLink(target, link string) error {
+	if err := os.MkdirAll(filepath.Dir(link), forwardDirMode); err != nil {
+		return err
+	}
+	if current, err := os.Readlink(link); err == nil && current == target {
+		return nil
+	}
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, link)
+}
+
+func bindMount(source, target string, readOnly bool) mount.Mount {
+	return mount.Mount{Type: mount.TypeBind, Source: source, Target: target, ReadOnly: readOnly}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
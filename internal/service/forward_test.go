@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestPrepareForwardsRefreshesSocketLink(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	first := filepath.Join(t.TempDir(), "agent.1")
+	second := filepath.Join(t.TempDir(), "agent.2")
+	for _, p := range []string{first, second} {
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatalf("write socket stand-in: %v", err)
+		}
+	}
+
+	link := filepath.Join(service.ForwardDir(), "ssh-agent.sock")
+	for _, socket := range []string{first, second} {
+		t.Setenv("SSH_AUTH_SOCK", socket)
+		if err := service.PrepareForwards(context.Background(), []config.ForwardKind{config.ForwardSSHAgent}); err != nil {
+			t.Fatalf("PrepareForwards error: %v", err)
+		}
+		got, err := os.Readlink(link)
+		if err != nil {
+			t.Fatalf("readlink: %v", err)
+		}
+		if got != socket {
+			t.Fatalf("unexpected link target: got %q want %q", got, socket)
+		}
+	}
+}
+
+func TestPrepareForwardsMissingAgent(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if err := service.PrepareForwards(context.Background(), []config.ForwardKind{config.ForwardSSHAgent}); err == nil {
+		t.Fatalf("expected error when SSH_AUTH_SOCK is unset")
+	}
+}
+
+func TestBuildContainerCreateOptionsForwards(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[user]\n"), 0o600); err != nil {
+		t.Fatalf("write gitconfig: %v", err)
+	}
+
+	run := config.RunSpec{Forward: []config.ForwardKind{config.ForwardSSHAgent, config.ForwardGitConfig}}
+	opts, err := service.BuildContainerCreateOptions("demo", run, "image:tag", "fp", false, false, false)
+	if err != nil {
+		t.Fatalf("BuildContainerCreateOptions error: %v", err)
+	}
+
+	if !slices.Contains(opts.Config.Env, "SSH_AUTH_SOCK=/run/cradle/ssh-agent.sock") {
+		t.Fatalf("expected SSH_AUTH_SOCK env, got %v", opts.Config.Env)
+	}
+	if !slices.Contains(opts.Config.Env, "GIT_CONFIG_GLOBAL=/run/cradle/gitconfig") {
+		t.Fatalf("expected GIT_CONFIG_GLOBAL env, got %v", opts.Config.Env)
+	}
+
+	sources := map[string]string{}
+	for _, m := range opts.HostConfig.Mounts {
+		sources[m.Target] = m.Source
+	}
+	if want := filepath.Join(service.ForwardDir(), "ssh-agent.sock"); sources["/run/cradle/ssh-agent.sock"] != want {
+		t.Fatalf("expected ssh socket mounted from stable link %q, got %+v", want, sources)
+	}
+	if sources["/run/cradle/gitconfig"] != filepath.Join(home, ".gitconfig") {
+		t.Fatalf("unexpected gitconfig mount: %+v", sources)
+	}
+}
+
+func TestRunFingerprintIgnoresAgentSocketPath(t *testing.T) {
+	run := config.RunSpec{Forward: []config.ForwardKind{config.ForwardSSHAgent}}
+
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/ssh-a/agent.1")
+	first, err := service.RunFingerprint("alias", "name", "img", "id", run, false, false, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/ssh-b/agent.2")
+	second, err := service.RunFingerprint("alias", "name", "img", "id", run, false, false, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	if first != second {
+		t.Fatalf("expected fingerprint to ignore agent socket path")
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+const (
+	gitCacheDirPerm = 0o755
+	gitURLKeyLength = 12
+)
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// GitCacheDir returns the directory that holds cached git build contexts.
+func GitCacheDir() string {
+	return cacheDir("git")
+}
+
+// PrepareGitContext resolves spec.Ref to a commit, makes sure a checkout of that
+// commit exists under cacheRoot and returns the context directory inside it.
+// The host git runs the fetch, so its credential helpers and config apply.
+func PrepareGitContext(ctx context.Context, spec *config.GitContextSpec, cacheRoot string) (string, error) {
+	commit, err := resolveGitCommit(ctx, spec.URL, spec.Ref)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(spec.URL))
+	dir := filepath.Join(cacheRoot, hex.EncodeToString(sum[:])[:gitURLKeyLength], commit)
+	if _, statErr := os.Stat(dir); errors.Is(statErr, os.ErrNotExist) {
+		if err = checkoutGitCommit(ctx, spec.URL, spec.Ref, commit, dir); err != nil {
+			return "", err
+		}
+	} else if statErr != nil {
+		return "", statErr
+	}
+
+	contextDir := filepath.Join(dir, filepath.FromSlash(spec.Subdir))
+	info, err := os.Stat(contextDir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("git context: %s has no directory %q at %s", spec.URL, spec.Subdir, commit)
+	}
+	return contextDir, nil
+}
+
+// resolveGitCommit returns the commit ref points to. Full commit IDs are used as is.
+func resolveGitCommit(ctx context.Context, url, ref string) (string, error) {
+	if commitPattern.MatchString(ref) {
+		return ref, nil
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := runGit(ctx, "", "ls-remote", url, ref, ref+"^{}")
+	if err != nil {
+		return "", err
+	}
+	refs := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		commit, name, ok := strings.Cut(scanner.Text(), "\t")
+		if ok {
+			refs[name] = commit
+		}
+	}
+	for _, name := range []string{ref, "refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref} {
+		if commit, ok := refs[name]; ok {
+			return commit, nil
+		}
+	}
+	return "", fmt.Errorf("git context: ref %q not found in %s", ref, url)
+}
+
+// checkoutGitCommit shallow-fetches commit into dir. The checkout is staged next to
+// dir and renamed into place so an interrupted fetch never leaves a partial cache.
+func checkoutGitCommit(ctx context.Context, url, ref, commit, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), gitCacheDirPerm); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Fetch by ref name where possible; not every server serves arbitrary commits.
+	want := ref
+	if want == "" {
+		want = "HEAD"
+	}
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", url, want},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if _, err = runGit(ctx, tmp, args...); err != nil {
+			return err
+		}
+	}
+	head, err := runGit(ctx, tmp, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if got := strings.TrimSpace(string(head)); got != commit {
+		return fmt.Errorf("git context: %s moved to %s while fetching %s", url, got, commit)
+	}
+	if err = os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil // another build cached the same commit first
+		}
+		return err
+	}
+	return nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Fail instead of waiting on a password prompt; credential helpers still run.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
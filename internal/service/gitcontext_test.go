@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+// gitRepo creates a repository with an images/dev/Dockerfile, tagged v1, and returns
+// its path and the commit ID.
+func gitRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "images", "dev"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dockerfile := filepath.Join(dir, "images", "dev", "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM alpine\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "init")
+	git("tag", "-a", "v1", "-m", "v1")
+	return dir, git("rev-parse", "HEAD")
+}
+
+func TestPrepareGitContext(t *testing.T) {
+	repo, commit := gitRepo(t)
+	cache := t.TempDir()
+	ctx := context.Background()
+
+	for _, ref := range []string{"", "main", "v1", commit} {
+		spec := &config.GitContextSpec{URL: "file://" + repo, Ref: ref, Subdir: "images/dev"}
+		dir, err := service.PrepareGitContext(ctx, spec, cache)
+		if err != nil {
+			t.Fatalf("ref %q: PrepareGitContext error: %v", ref, err)
+		}
+		if filepath.Base(filepath.Dir(filepath.Dir(dir))) != commit {
+			t.Fatalf("ref %q: expected cache keyed by commit, got %s", ref, dir)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "Dockerfile")); err != nil {
+			t.Fatalf("ref %q: expected Dockerfile in context: %v", ref, err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "..", "..", ".git")); err == nil {
+			t.Fatalf("ref %q: expected .git to be removed from the cache", ref)
+		}
+	}
+}
+
+func TestPrepareGitContextErrors(t *testing.T) {
+	repo, _ := gitRepo(t)
+	cache := t.TempDir()
+	ctx := context.Background()
+
+	spec := &config.GitContextSpec{URL: "file://" + repo, Ref: "nope"}
+	if _, err := service.PrepareGitContext(ctx, spec, cache); err == nil {
+		t.Fatalf("expected unknown ref error")
+	}
+	spec = &config.GitContextSpec{URL: "file://" + repo, Subdir: "missing"}
+	if _, err := service.PrepareGitContext(ctx, spec, cache); err == nil {
+		t.Fatalf("expected missing subdir error")
+	}
+}
+
+func TestGitCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache")
+	if got := service.GitCacheDir(); got != "/tmp/cache/cradle/git" {
+		t.Fatalf("unexpected cache dir: %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+type HookStage string
+
+const (
+	HookPreBuild  HookStage = "pre_build"
+	HookPostBuild HookStage = "post_build"
+	HookPreRun    HookStage = "pre_run"
+	HookPostRun   HookStage = "post_run"
+)
+
+// HookEnv describes the alias metadata exposed to host hooks.
+type HookEnv struct {
+	Stage         HookStage
+	Alias         string
+	Instance      string
+	ImageRef      string
+	ContainerName string
+	ContainerID   string
+	ConfigDir     string
+}
+
+// Environ returns the host environment extended with the CRADLE_* hook variables.
+func (e HookEnv) Environ() []string {
+	return append(os.Environ(),
+		"CRADLE_HOOK="+string(e.Stage),
+		"CRADLE_ALIAS="+e.Alias,
+		"CRADLE_INSTANCE="+e.Instance,
+		"CRADLE_IMAGE="+e.ImageRef,
+		"CRADLE_CONTAINER_NAME="+e.ContainerName,
+		"CRADLE_CONTAINER_ID="+e.ContainerID,
+		"CRADLE_CONFIG_DIR="+e.ConfigDir,
+	)
+}
+
+// RunHooks runs each command through /bin/sh in dir and stops at the first failure.
+func RunHooks(ctx context.Context, dir string, commands []string, env HookEnv, out io.Writer) error {
+	if out == nil {
+		out = io.Discard
+	}
+	for _, command := range commands {
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command) //nolint:gosec // hooks come from the user's config
+		cmd.Dir = dir
+		cmd.Env = env.Environ()
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q: %w", env.Stage, command, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) runHooks(
+	ctx context.Context,
+	stage HookStage,
+	commands []string,
+	env HookEnv,
+	out io.Writer,
+) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	env.Stage = stage
+	env.ConfigDir = s.cfg.BaseDir
+	return RunHooks(ctx, s.cfg.BaseDir, commands, env, out)
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestRunHooksEnvAndDir(t *testing.T) {
+	dir := t.TempDir()
+	env := service.HookEnv{
+		Stage:         service.HookPostRun,
+		Alias:         "demo",
+		ImageRef:      "cradle/demo:latest",
+		ContainerName: "cradle-demo",
+		ContainerID:   "abc123",
+		ConfigDir:     dir,
+	}
+	commands := []string{
+		`printf '%s|%s|%s|%s|%s' "$CRADLE_HOOK" "$CRADLE_ALIAS" "$CRADLE_IMAGE" ` +
+			`"$CRADLE_CONTAINER_NAME" "$CRADLE_CONTAINER_ID" > hook.out`,
+	}
+
+	if err := service.RunHooks(context.Background(), dir, commands, env, nil); err != nil {
+		t.Fatalf("RunHooks error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "hook.out"))
+	if err != nil {
+		t.Fatalf("read hook output: %v", err)
+	}
+	want := "post_run|demo|cradle/demo:latest|cradle-demo|abc123"
+	if string(data) != want {
+		t.Fatalf("unexpected hook output: got %q want %q", string(data), want)
+	}
+}
+
+func TestRunHooksStopsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	env := service.HookEnv{Stage: service.HookPreBuild, Alias: "demo"}
+	commands := []string{"exit 3", "touch never"}
+
+	err := service.RunHooks(context.Background(), dir, commands, env, nil)
+	if err == nil {
+		t.Fatalf("expected hook failure")
+	}
+	if !strings.Contains(err.Error(), "pre_build") {
+		t.Fatalf("expected stage in error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "never")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected later hooks to be skipped")
+	}
+}
+
+func TestEnsureImageSkipsBuildHooksForPulls(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	stream := fakePull{stream: `{"status":"Status: Image is up to date for ubuntu:24.04"}` + "\n"}
+	svc := newFakeDaemonService(t, stream, &config.Config{Aliases: map[string]config.Alias{
+		"base": {
+			Image:     config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04", Policy: config.ImagePolicyAlways}},
+			PreBuild:  []string{"false"},
+			PostBuild: []string{"false"},
+		},
+	}})
+
+	if _, err := svc.EnsureImage(context.Background(), "base", io.Discard, service.ImagePolicyOverrides{}); err != nil {
+		t.Fatalf("expected build hooks to be skipped for a pull, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"maps"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+const hostUserArg0 = "cradle-host-user"
+
+// hostUserScript runs as root at container start. It maps the caller's identity onto
+// /etc/passwd, /etc/group and /etc/shadow directly so it works without shadow-utils,
+// then drops privileges and execs the original command.
+const hostUserScript = `set -eu
+uid="$CRADLE_HOST_UID"
+gid="$CRADLE_HOST_GID"
+name="$CRADLE_HOST_USER"
+home="$CRADLE_HOST_HOME"
+shell="$CRADLE_HOST_SHELL"
+[ -x "$shell" ] || shell=/bin/bash
+[ -x "$shell" ] || shell=/bin/sh
+[ "$#" -gt 0 ] || set -- "$shell"
+[ "$(id -u)" = 0 ] || exec "$@"
+
+edit() {
+  file="$1"
+  shift
+  tmp="$(mktemp)"
+  awk -F: -v OFS=: "$@" "$file" > "$tmp"
+  cat "$tmp" > "$file"
+  rm -f "$tmp"
+}
+group_by_id() { awk -F: -v id="$1" '$3 == id { print $1; exit }' /etc/group; }
+group_by_name() { awk -F: -v n="$1" '$1 == n { print $1; exit }' /etc/group; }
+
+if [ -z "$(group_by_id "$gid")" ]; then
+  edit /etc/group -v n="$name" '$1 != n'
+  echo "$name:x:$gid:" >> /etc/group
+fi
+
+edit /etc/passwd -v n="$name" -v id="$uid" '$1 != n && $3 != id'
+echo "$name:x:$uid:$gid::$home:$shell" >> /etc/passwd
+if [ -f /etc/shadow ]; then
+  edit /etc/shadow -v n="$name" '$1 != n'
+  echo "$name:*:1::::::" >> /etc/shadow
+fi
+
+for entry in $(echo "${CRADLE_HOST_GROUPS:-}" | tr ',' ' '); do
+  group="$(group_by_name "${entry#*:}")"
+  [ -n "$group" ] || group="$(group_by_id "${entry%%:*}")"
+  if [ -z "$group" ]; then
+    echo "${entry#*:}:x:${entry%%:*}:" >> /etc/group
+    group="${entry#*:}"
+  fi
+  edit /etc/group -v g="$group" -v u="$name" \
+    '$1 == g && ("," $4 ",") !~ ("," u ",") { $4 = ($4 == "" ? u : $4 "," u) } { print }'
+done
+
+if [ -d /etc/sudoers.d ]; then
+  echo "$name ALL=(ALL) NOPASSWD:ALL" > "/etc/sudoers.d/$name"
+  chmod 0440 "/etc/sudoers.d/$name"
+fi
+
+if [ ! -d "$home" ]; then
+  mkdir -p "$home"
+  chown "$uid:$gid" "$home"
+fi
+
+export HOME="$home" USER="$name" LOGNAME="$name" SHELL="$shell"
+if command -v setpriv > /dev/null 2>&1; then
+  exec setpriv --reuid="$uid" --regid="$gid" --init-groups -- "$@"
+fi
+for tool in su-exec gosu; do
+  if command -v "$tool" > /dev/null 2>&1; then
+    exec "$tool" "$name" "$@"
+  fi
+done
+if command -v runuser > /dev/null 2>&1; then
+  exec runuser -u "$name" -- "$@"
+fi
+exec su -s /bin/sh -c 'exec "$0" "$@"' "$name" "$@"
+`
+
+// HostIdentity is the caller's account as mirrored into host_user containers.
+type HostIdentity struct {
+	UID      string
+	GID      string
+	Username string
+	Home     string
+	Shell    string
+	Groups   []HostGroup
+}
+
+// HostGroup is a supplementary group of the caller.
+type HostGroup struct {
+	GID  string
+	Name string
+}
+
+// CurrentHostIdentity resolves the invoking user's identity and supplementary groups.
+func CurrentHostIdentity() (HostIdentity, error) {
+	u, err := user.Current()
+	if err != nil {
+		return HostIdentity{}, err
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	identity := HostIdentity{
+		UID:      u.Uid,
+		GID:      u.Gid,
+		Username: u.Username,
+		Home:     u.HomeDir,
+		Shell:    shell,
+	}
+
+	gids, err := u.GroupIds()
+	if err != nil {
+		return identity, nil //nolint:nilerr // supplementary groups are best effort
+	}
+	for _, gid := range gids {
+		if gid == u.Gid {
+			continue
+		}
+		g, lookupErr := user.LookupGroupId(gid)
+		if lookupErr != nil {
+			continue
+		}
+		identity.Groups = append(identity.Groups, HostGroup{GID: gid, Name: g.Name})
+	}
+	return identity, nil
+}
+
+// ApplyHostUser rewrites run so the container starts as root, mirrors identity and
+// then runs the effective command (run or image entrypoint/cmd) as that user.
+func ApplyHostUser(
+	run config.RunSpec,
+	identity HostIdentity,
+	imageEntrypoint, imageCmd []string,
+) config.RunSpec {
+	if identity.UID == "" || identity.UID == "0" {
+		return run
+	}
+
+	command := slices.Concat(imageEntrypoint, imageCmd)
+	if len(run.Entrypoint) > 0 {
+		command = slices.Concat(run.Entrypoint, run.Cmd)
+	} else if len(run.Cmd) > 0 {
+		command = slices.Concat(imageEntrypoint, run.Cmd)
+	}
+
+	groups := make([]string, 0, len(identity.Groups))
+	for _, g := range identity.Groups {
+		groups = append(groups, g.GID+":"+g.Name)
+	}
+
+	env := maps.Clone(run.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	env["CRADLE_HOST_UID"] = identity.UID
+	env["CRADLE_HOST_GID"] = identity.GID
+	env["CRADLE_HOST_USER"] = identity.Username
+	env["CRADLE_HOST_HOME"] = identity.Home
+	env["CRADLE_HOST_SHELL"] = identity.Shell
+	env["CRADLE_HOST_GROUPS"] = strings.Join(groups, ",")
+
+	run.Env = env
+	run.User = "0:0"
+	run.UID = 0
+	run.GID = 0
+	run.Entrypoint = []string{"/bin/sh", "-c", hostUserScript, hostUserArg0}
+	run.Cmd = command
+	return run
+}
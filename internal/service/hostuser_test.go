@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestApplyHostUser(t *testing.T) {
+	identity := service.HostIdentity{
+		UID:      "1000",
+		GID:      "1000",
+		Username: "alice",
+		Home:     "/home/alice",
+		Shell:    "/bin/zsh",
+		Groups:   []service.HostGroup{{GID: "44", Name: "video"}, {GID: "999", Name: "docker"}},
+	}
+	run := config.RunSpec{
+		HostUser: true,
+		Env:      map[string]string{"A": "1"},
+		Cmd:      []string{"bash"},
+	}
+
+	got := service.ApplyHostUser(run, identity, []string{"/init"}, []string{"sleep", "infinity"})
+
+	if got.User != "0:0" {
+		t.Fatalf("expected container to start as root, got %q", got.User)
+	}
+	if len(got.Entrypoint) != 4 || got.Entrypoint[0] != "/bin/sh" || got.Entrypoint[1] != "-c" {
+		t.Fatalf("unexpected entrypoint: %#v", got.Entrypoint)
+	}
+	if want := []string{"/init", "bash"}; !reflect.DeepEqual(got.Cmd, want) {
+		t.Fatalf("unexpected cmd: got %#v want %#v", got.Cmd, want)
+	}
+	if got.Env["CRADLE_HOST_USER"] != "alice" || got.Env["CRADLE_HOST_UID"] != "1000" {
+		t.Fatalf("unexpected identity env: %+v", got.Env)
+	}
+	if got.Env["CRADLE_HOST_GROUPS"] != "44:video,999:docker" {
+		t.Fatalf("unexpected groups env: %q", got.Env["CRADLE_HOST_GROUPS"])
+	}
+	if got.Env["A"] != "1" {
+		t.Fatalf("expected existing env to be kept")
+	}
+	if _, ok := run.Env["CRADLE_HOST_USER"]; ok {
+		t.Fatalf("expected input env to be left untouched")
+	}
+}
+
+func TestApplyHostUserCommandResolution(t *testing.T) {
+	identity := service.HostIdentity{UID: "1000", GID: "1000", Username: "alice"}
+
+	got := service.ApplyHostUser(config.RunSpec{}, identity, []string{"/init"}, []string{"serve"})
+	if want := []string{"/init", "serve"}; !reflect.DeepEqual(got.Cmd, want) {
+		t.Fatalf("expected image command, got %#v", got.Cmd)
+	}
+
+	run := config.RunSpec{Entrypoint: []string{"/bin/bash"}, Cmd: []string{"-l"}}
+	got = service.ApplyHostUser(run, identity, []string{"/init"}, []string{"serve"})
+	if want := []string{"/bin/bash", "-l"}; !reflect.DeepEqual(got.Cmd, want) {
+		t.Fatalf("expected run entrypoint to replace image command, got %#v", got.Cmd)
+	}
+}
+
+func TestApplyHostUserRootIsNoop(t *testing.T) {
+	run := config.RunSpec{HostUser: true, Cmd: []string{"bash"}}
+	got := service.ApplyHostUser(run, service.HostIdentity{UID: "0", GID: "0"}, nil, nil)
+	if !reflect.DeepEqual(got, run) {
+		t.Fatalf("expected root identity to leave run unchanged, got %+v", got)
+	}
+}
+
+func TestRunFingerprintHostUser(t *testing.T) {
+	run := config.RunSpec{HostUser: true, Cmd: []string{"bash"}}
+	alice := service.ApplyHostUser(run, service.HostIdentity{UID: "1000", GID: "1000", Username: "alice"}, nil, nil)
+	bob := service.ApplyHostUser(run, service.HostIdentity{UID: "1001", GID: "1001", Username: "bob"}, nil, nil)
+
+	first, err := service.RunFingerprint("alias", "name", "img", "id", alice, true, true, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	second, err := service.RunFingerprint("alias", "name", "img", "id", bob, true, true, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	if first == second {
+		t.Fatalf("expected fingerprint to differ per host user")
+	}
+}
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -138,13 +139,25 @@ func TestRunReusesContainer(t *testing.T) {
 		)
 	}()
 
-	first, err := svc.Run(ctx, alias, io.Discard, service.ImagePolicyOverrides{})
+	first, err := svc.Run(
+		ctx,
+		service.Target{Alias: alias},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{},
+	)
 	if err != nil {
 		t.Fatalf("first run: %v", err)
 	}
 	waitForExit(t, cli, first.ID)
 
-	second, err := svc.Run(ctx, alias, io.Discard, service.ImagePolicyOverrides{})
+	second, err := svc.Run(
+		ctx,
+		service.Target{Alias: alias},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{},
+	)
 	if err != nil {
 		t.Fatalf("second run: %v", err)
 	}
@@ -166,7 +179,13 @@ func TestRunReusesContainer(t *testing.T) {
 		},
 	}
 
-	third, err := svc.Run(ctx, alias, io.Discard, service.ImagePolicyOverrides{})
+	third, err := svc.Run(
+		ctx,
+		service.Target{Alias: alias},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{},
+	)
 	if err != nil {
 		t.Fatalf("third run: %v", err)
 	}
@@ -248,12 +267,18 @@ func TestStopRunningContainer(t *testing.T) {
 		_, _ = cli.ContainerRemove(context.Background(), name, client.ContainerRemoveOptions{Force: true})
 	}()
 
-	run, err := svc.Run(context.Background(), "sleep", io.Discard, service.ImagePolicyOverrides{})
+	run, err := svc.Run(
+		context.Background(),
+		service.Target{Alias: "sleep"},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{},
+	)
 	if err != nil {
 		t.Fatalf("run error: %v", err)
 	}
 
-	if _, stopErr := svc.Stop(context.Background(), "sleep"); stopErr != nil {
+	if _, stopErr := svc.Stop(context.Background(), service.Target{Alias: "sleep"}, false); stopErr != nil {
 		t.Fatalf("stop error: %v", stopErr)
 	}
 
@@ -265,3 +290,182 @@ func TestStopRunningContainer(t *testing.T) {
 		t.Fatalf("expected container to be stopped")
 	}
 }
+
+func TestRunEphemeralLeavesNamedContainer(t *testing.T) {
+	cli := requireDocker(t)
+	const baseImage = "alpine:3.20"
+	requireImage(t, cli, baseImage)
+
+	name := fmt.Sprintf("cradle-ephemeral-%d", time.Now().UnixNano())
+	attach := false
+	autoRemove := false
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"sleep": {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: baseImage}},
+				Run: config.RunSpec{
+					Name:       name,
+					Attach:     &attach,
+					AutoRemove: &autoRemove,
+					Cmd:        []string{"sh", "-lc", "sleep 60"},
+				},
+			},
+		},
+	}
+
+	svc, err := service.New(cfg)
+	if err != nil {
+		t.Fatalf("service init: %v", err)
+	}
+	defer func() { _ = svc.Close() }()
+	defer func() {
+		_, _ = cli.ContainerRemove(context.Background(), name, client.ContainerRemoveOptions{Force: true})
+	}()
+
+	ctx := context.Background()
+	named, err := svc.Run(
+		ctx,
+		service.Target{Alias: "sleep"},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{},
+	)
+	if err != nil {
+		t.Fatalf("named run: %v", err)
+	}
+	ephemeral, err := svc.Run(
+		ctx,
+		service.Target{Alias: "sleep"},
+		io.Discard,
+		service.ImagePolicyOverrides{},
+		service.RunOptions{Ephemeral: true},
+	)
+	if err != nil {
+		t.Fatalf("ephemeral run: %v", err)
+	}
+	if ephemeral.ID == named.ID || !ephemeral.AutoRemove {
+		t.Fatalf("expected a separate auto-removed container, got %+v", ephemeral)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if attachErr := svc.AttachAndWait(cancelled, service.AttachOptions{
+		ID:         ephemeral.ID,
+		AutoRemove: true,
+		Stdout:     io.Discard,
+	}); attachErr == nil {
+		t.Fatalf("expected error for cancelled attach")
+	}
+	_, inspectErr := cli.ContainerInspect(ctx, ephemeral.ID, client.ContainerInspectOptions{})
+	if !errdefs.IsNotFound(inspectErr) {
+		t.Fatalf("expected ephemeral container to be removed, got %v", inspectErr)
+	}
+
+	ctr, err := cli.ContainerInspect(ctx, named.ID, client.ContainerInspectOptions{})
+	if err != nil {
+		t.Fatalf("inspect named: %v", err)
+	}
+	if ctr.Container.State == nil || !ctr.Container.State.Running {
+		t.Fatalf("expected named container to keep running")
+	}
+}
+
+func TestCommitSnapshot(t *testing.T) {
+	cli := requireDocker(t)
+	const baseImage = "alpine:3.20"
+	requireImage(t, cli, baseImage)
+
+	name := fmt.Sprintf("cradle-commit-%d", time.Now().UnixNano())
+	alias := fmt.Sprintf("commit%d", time.Now().UnixNano())
+	attach := false
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			alias: {
+				Image: config.ImageSpec{Pull: &config.PullSpec{Ref: baseImage}, Snapshot: true},
+				Run: config.RunSpec{
+					Name:   name,
+					Attach: &attach,
+					Cmd:    []string{"sh", "-lc", "touch /tuned && sleep 60"},
+				},
+			},
+		},
+	}
+
+	svc, err := service.New(cfg)
+	if err != nil {
+		t.Fatalf("service init: %v", err)
+	}
+	defer func() { _ = svc.Close() }()
+	defer func() {
+		_, _ = cli.ContainerRemove(context.Background(), name, client.ContainerRemoveOptions{Force: true})
+	}()
+
+	ctx := context.Background()
+	target := service.Target{Alias: alias}
+	if _, runErr := svc.Run(ctx, target, io.Discard, service.ImagePolicyOverrides{}, service.RunOptions{}); runErr != nil {
+		t.Fatalf("run error: %v", runErr)
+	}
+	ref, err := svc.Commit(ctx, target, false, "")
+	if err != nil {
+		t.Fatalf("commit error: %v", err)
+	}
+	defer func() {
+		_, _ = cli.ImageRemove(context.Background(), ref, client.ImageRemoveOptions{Force: true})
+	}()
+
+	snapshots, err := svc.Snapshots(ctx, alias)
+	if err != nil {
+		t.Fatalf("snapshots error: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Ref != ref {
+		t.Fatalf("expected snapshot %q, got %+v", ref, snapshots)
+	}
+}
+
+func TestSaveLoadBundle(t *testing.T) {
+	cli := requireDocker(t)
+	const baseImage = "alpine:3.20"
+	requireImage(t, cli, baseImage)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	alias := fmt.Sprintf("bundle%d", time.Now().UnixNano())
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			alias: {Image: config.ImageSpec{Pull: &config.PullSpec{Ref: baseImage}}},
+		},
+	}
+
+	svc, err := service.New(cfg)
+	if err != nil {
+		t.Fatalf("service init: %v", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	ctx := context.Background()
+	var bundle bytes.Buffer
+	saved, err := svc.Save(ctx, []string{alias}, &bundle)
+	if err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	loaded, err := svc.Load(ctx, &bundle, io.Discard)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	defer func() {
+		_, _ = cli.ImageRemove(context.Background(), "cradle/"+alias+":latest", client.ImageRemoveOptions{})
+	}()
+	if len(loaded.Images) != 1 || loaded.Images[0].ImageID != saved.Images[0].ImageID {
+		t.Fatalf("unexpected manifest: %+v", loaded)
+	}
+	if len(loaded.Config) == 0 {
+		t.Fatalf("expected the bundled config")
+	}
+
+	items, err := svc.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("list statuses error: %v", err)
+	}
+	if len(items) == 0 || items[0].Bundle != service.BundleMatch {
+		t.Fatalf("expected bundle match, got %+v", items)
+	}
+}
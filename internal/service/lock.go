@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/client"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the lock file written next to the config by `cradle lock`.
+const LockFileName = "cradle.lock"
+
+const (
+	lockVersion  = 1
+	lockFilePerm = 0o644
+	lockHeader   = "# Generated by cradle lock. Do not edit by hand.\n"
+)
+
+// Lock pins alias images to registry digests.
+type Lock struct {
+	Version int                  `yaml:"version"`
+	Aliases map[string]LockEntry `yaml:"aliases"`
+}
+
+// LockEntry pins a pull alias image, or the base images of a build alias.
+type LockEntry struct {
+	Ref    string        `yaml:"ref,omitempty"`
+	Digest string        `yaml:"digest,omitempty"`
+	Bases  []LockedImage `yaml:"bases,omitempty"`
+}
+
+// LockedImage is an image reference and the digest it resolved to.
+type LockedImage struct {
+	Ref    string `yaml:"ref"`
+	Digest string `yaml:"digest"`
+}
+
+// LockPath returns the lock file path for cfg, or "" when cfg was not loaded from a file.
+func LockPath(cfg *config.Config) string {
+	if cfg == nil || cfg.BaseDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.BaseDir, LockFileName)
+}
+
+// ReadLock reads the lock file at path. A missing file yields an empty lock.
+func ReadLock(path string) (*Lock, error) {
+	empty := &Lock{Version: lockVersion, Aliases: map[string]LockEntry{}}
+	if path == "" {
+		return empty, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err = yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if lock.Aliases == nil {
+		lock.Aliases = map[string]LockEntry{}
+	}
+	if lock.Version != lockVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d", path, lock.Version)
+	}
+	return &lock, nil
+}
+
+// WriteLock writes lock to path.
+func WriteLock(path string, lock *Lock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(lockHeader), data...), lockFilePerm)
+}
+
+// PinnedRef returns ref's repository pinned to digest.
+func PinnedRef(ref, digest string) string {
+	return repository(ref) + "@" + digest
+}
+
+// repository strips the tag and digest from ref.
+func repository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	slash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > slash {
+		return ref[:colon]
+	}
+	return ref
+}
+
+// Lock resolves alias images to digests and writes the lock file. With update set,
+// only those aliases are resolved and the other entries are kept.
+func (s *Service) Lock(ctx context.Context, update []string) (*Lock, error) {
+	path := LockPath(s.cfg)
+	if path == "" {
+		return nil, errors.New("lock needs a config file")
+	}
+	lock := &Lock{Version: lockVersion, Aliases: map[string]LockEntry{}}
+	names := update
+	if len(update) == 0 {
+		for _, info := range s.ListAliases() {
+			names = append(names, info.Name)
+		}
+	} else {
+		existing, err := ReadLock(path)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(lock.Aliases, existing.Aliases)
+	}
+
+	for _, name := range names {
+		entry, err := s.lockEntry(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("lock %s: %w", name, err)
+		}
+		lock.Aliases[name] = entry
+	}
+	if err := WriteLock(path, lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (s *Service) lockEntry(ctx context.Context, name string) (LockEntry, error) {
+	a, ok := s.cfg.Aliases[name]
+	if !ok {
+		return LockEntry{}, fmt.Errorf("unknown alias %q", name)
+	}
+	if a.Image.Pull != nil {
+		ref := NormalizeImageRef(a.Image.Pull.Ref)
+		options, err := PullOptionsFromSpec(a.Image.Pull)
+		if err != nil {
+			return LockEntry{}, err
+		}
+		digest, err := s.resolveDigest(ctx, ref, options.RegistryAuth)
+		if err != nil {
+			return LockEntry{}, err
+		}
+		return LockEntry{Ref: ref, Digest: digest}, nil
+	}
+
+	bases, err := BaseImages(a.Image.Build)
+	if err != nil {
+		return LockEntry{}, err
+	}
+	entry := LockEntry{}
+	for _, ref := range bases {
+		auth, authErr := buildRegistryAuth(a.Image.Build, ref)
+		if authErr != nil {
+			return LockEntry{}, authErr
+		}
+		digest, resolveErr := s.resolveDigest(ctx, ref, auth)
+		if resolveErr != nil {
+			return LockEntry{}, resolveErr
+		}
+		entry.Bases = append(entry.Bases, LockedImage{Ref: ref, Digest: digest})
+	}
+	return entry, nil
+}
+
+func (s *Service) resolveDigest(ctx context.Context, ref, auth string) (string, error) {
+	res, err := s.cli.DistributionInspect(ctx, ref, client.DistributionInspectOptions{EncodedRegistryAuth: auth})
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	return string(res.Descriptor.Digest), nil
+}
+
+// lockedEntry returns the lock entry for alias, if the lock file has one.
+func (s *Service) lockedEntry(alias string) (LockEntry, bool, error) {
+	lock, err := ReadLock(LockPath(s.cfg))
+	if err != nil {
+		return LockEntry{}, false, err
+	}
+	entry, ok := lock.Aliases[alias]
+	return entry, ok, nil
+}
+
+// pinnedPullRef returns the digest reference to pull for ref when the lock pins it.
+// An entry recorded for a different ref is stale and ignored.
+func (s *Service) pinnedPullRef(alias, ref string) (string, error) {
+	entry, ok, err := s.lockedEntry(alias)
+	if err != nil || !ok || entry.Ref != ref || entry.Digest == "" {
+		return "", err
+	}
+	return PinnedRef(ref, entry.Digest), nil
+}
+
+// pinnedBuildSpec returns b with its Dockerfile rewritten to start from the locked
+// base digests, or b itself when the lock pins no bases for alias. The rewritten
+// Dockerfile is passed inline, so the daemon pulls the pinned images with the
+// build's auth_configs and no shared tag is changed.
+func (s *Service) pinnedBuildSpec(alias string, b *config.BuildSpec) (*config.BuildSpec, error) {
+	entry, ok, err := s.lockedEntry(alias)
+	if err != nil || !ok || len(entry.Bases) == 0 {
+		return b, err
+	}
+	data, err := dockerfileSource(b)
+	if err != nil || data == nil {
+		return b, err
+	}
+	pins := make(map[string]string, len(entry.Bases))
+	for _, base := range entry.Bases {
+		pins[base.Ref] = PinnedRef(base.Ref, base.Digest)
+	}
+	pinned := *b
+	pinned.DockerfileInline = string(PinDockerfile(data, b.Args, pins))
+	pinned.Dockerfile = ""
+	pinned.Recipe = nil
+	return &pinned, nil
+}
+
+func (s *Service) tagImage(ctx context.Context, source, target string) error {
+	if _, err := s.cli.ImageTag(ctx, client.ImageTagOptions{Source: source, Target: target}); err != nil {
+		return fmt.Errorf("tag %s as %s: %w", source, target, err)
+	}
+	return nil
+}
+
+// BaseImages returns the external images the Dockerfile of b starts from, in order.
+// Build stages, scratch, digest-pinned images and references with unresolved
+// variables are skipped. Variables are resolved from the build args. Remote and git
+// contexts are not fetched, so only their generated Dockerfiles are read.
+func BaseImages(b *config.BuildSpec) ([]string, error) {
+	data, err := dockerfileSource(b)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return ParseBaseImages(data, b.Args), nil
+}
+
+// dockerfileSource returns the Dockerfile b builds, or nil when it is not readable
+// without fetching a remote or git context.
+func dockerfileSource(b *config.BuildSpec) ([]byte, error) {
+	if b == nil || b.RemoteContext != "" {
+		return nil, nil
+	}
+	generated, err := GeneratedDockerfile(b)
+	if err != nil {
+		return nil, err
+	}
+	if generated != "" {
+		return []byte(generated), nil
+	}
+	if b.Git != nil {
+		return nil, nil
+	}
+	dockerfile := b.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(b.Cwd, dockerfile)
+	}
+	return os.ReadFile(dockerfile)
+}
+
+// ParseBaseImages extracts the FROM images of a Dockerfile. ARG defaults declared
+// before a FROM apply unless args overrides them.
+func ParseBaseImages(dockerfile []byte, args map[string]string) []string {
+	var out []string
+	walkBaseImages(dockerfile, args, func(_ int, _ string, ref string) {
+		if !slices.Contains(out, ref) {
+			out = append(out, ref)
+		}
+	})
+	return out
+}
+
+// PinDockerfile replaces every FROM image found in pins with its pinned reference.
+// Other lines are kept as they are.
+func PinDockerfile(dockerfile []byte, args map[string]string, pins map[string]string) []byte {
+	lines := strings.Split(string(dockerfile), "\n")
+	walkBaseImages(dockerfile, args, func(line int, token, ref string) {
+		pinned, ok := pins[ref]
+		if !ok {
+			return
+		}
+		text := lines[line]
+		// The image is the first field after FROM and its flags.
+		start := strings.Index(strings.ToUpper(text), "FROM") + len("FROM")
+		for _, field := range strings.Fields(text[start:]) {
+			at := strings.Index(text[start:], field) + start
+			if !strings.HasPrefix(field, "--") {
+				lines[line] = text[:at] + pinned + text[at+len(token):]
+				return
+			}
+			start = at + len(field)
+		}
+	})
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// walkBaseImages calls fn with the line index, the image token as written and the
+// resolved reference of every FROM line that names an external image. Build stages,
+// scratch, digest-pinned images and unresolved variables are skipped.
+func walkBaseImages(dockerfile []byte, args map[string]string, fn func(line int, token, ref string)) {
+	vars := map[string]string{}
+	stages := map[string]bool{}
+	for i, line := range strings.Split(string(dockerfile), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			name, value, _ := strings.Cut(fields[1], "=")
+			if override, ok := args[name]; ok {
+				value = override
+			}
+			vars[name] = value
+		case "FROM":
+			fields = slices.DeleteFunc(fields[1:], func(f string) bool { return strings.HasPrefix(f, "--") })
+			if len(fields) == 0 {
+				continue
+			}
+			ref, resolved := expandVars(fields[0], vars)
+			if resolved && ref != "scratch" && !stages[strings.ToLower(ref)] && !strings.Contains(ref, "@") {
+				fn(i, fields[0], ref)
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+				stages[strings.ToLower(fields[2])] = true
+			}
+		}
+	}
+}
+
+// expandVars substitutes $VAR and ${VAR} from vars and reports whether every
+// variable had a non-empty value.
+func expandVars(s string, vars map[string]string) (string, bool) {
+	resolved := true
+	out := os.Expand(s, func(key string) string {
+		value := vars[key]
+		if value == "" {
+			resolved = false
+		}
+		return value
+	})
+	return out, resolved && out != ""
+}
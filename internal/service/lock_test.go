@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestPinnedRef(t *testing.T) {
+	cases := map[string]string{
+		"ubuntu:24.04":                           "ubuntu@sha256:abc",
+		"ubuntu":                                 "ubuntu@sha256:abc",
+		"lscr.io/linuxserver/code-server:latest": "lscr.io/linuxserver/code-server@sha256:abc",
+		"localhost:5000/app":                     "localhost:5000/app@sha256:abc",
+		"localhost:5000/app:v1@sha256:old":       "localhost:5000/app@sha256:abc",
+	}
+	for ref, want := range cases {
+		if got := service.PinnedRef(ref, "sha256:abc"); got != want {
+			t.Fatalf("PinnedRef(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestParseBaseImages(t *testing.T) {
+	dockerfile := []byte(`ARG BASE=ubuntu:22.04
+ARG GO
+FROM --platform=$BUILDPLATFORM golang:1.25 AS build
+FROM build AS test
+FROM ${BASE}
+from scratch
+FROM golang:${GO}
+FROM alpine@sha256:abc
+FROM golang:1.25
+`)
+	got := service.ParseBaseImages(dockerfile, map[string]string{"BASE": "ubuntu:24.04"})
+	want := []string{"golang:1.25", "ubuntu:24.04"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseBaseImages = %v, want %v", got, want)
+	}
+}
+
+func TestPinDockerfile(t *testing.T) {
+	dockerfile := []byte(`ARG BASE=ubuntu:22.04
+FROM --platform=$BUILDPLATFORM golang:1.25 AS build
+FROM build AS test
+FROM ${BASE}
+FROM alpine:3.20
+FROM golang:1.25
+`)
+	pins := map[string]string{
+		"golang:1.25":  "golang@sha256:aaa",
+		"ubuntu:24.04": "ubuntu@sha256:bbb",
+	}
+	got := service.PinDockerfile(dockerfile, map[string]string{"BASE": "ubuntu:24.04"}, pins)
+	want := `ARG BASE=ubuntu:22.04
+FROM --platform=$BUILDPLATFORM golang@sha256:aaa AS build
+FROM build AS test
+FROM ubuntu@sha256:bbb
+FROM alpine:3.20
+FROM golang@sha256:aaa
+`
+	if string(got) != want {
+		t.Fatalf("PinDockerfile =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBaseImagesReadsDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile.dev"), []byte("FROM debian:12\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := service.BaseImages(&config.BuildSpec{Cwd: dir, Dockerfile: "Dockerfile.dev"})
+	if err != nil {
+		t.Fatalf("BaseImages error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"debian:12"}) {
+		t.Fatalf("unexpected bases: %v", got)
+	}
+}
+
+func TestLockRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := service.LockPath(&config.Config{BaseDir: dir})
+	if path != filepath.Join(dir, service.LockFileName) {
+		t.Fatalf("unexpected lock path: %q", path)
+	}
+	if service.LockPath(&config.Config{}) != "" {
+		t.Fatalf("expected no lock path without a config file")
+	}
+
+	empty, err := service.ReadLock(path)
+	if err != nil || len(empty.Aliases) != 0 {
+		t.Fatalf("expected empty lock, got %+v, %v", empty, err)
+	}
+
+	lock := &service.Lock{Version: 1, Aliases: map[string]service.LockEntry{
+		"code": {Ref: "ubuntu:24.04", Digest: "sha256:abc"},
+		"dev":  {Bases: []service.LockedImage{{Ref: "golang:1.25", Digest: "sha256:def"}}},
+	}}
+	if err = service.WriteLock(path, lock); err != nil {
+		t.Fatalf("WriteLock error: %v", err)
+	}
+	got, err := service.ReadLock(path)
+	if err != nil {
+		t.Fatalf("ReadLock error: %v", err)
+	}
+	if !reflect.DeepEqual(got, lock) {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestReadLockRejectsVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), service.LockFileName)
+	if err := os.WriteFile(path, []byte("version: 9\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ReadLock(path); err == nil {
+		t.Fatalf("expected version error")
+	}
+}
+
+func TestLockUsesBuildAuthForBases(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := []byte("FROM registry.example.com/base:1\n")
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), dockerfile, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var auth string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/distribution/") {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("X-Registry-Auth")
+		_ = json.NewEncoder(w).Encode(map[string]any{"Descriptor": map[string]any{
+			"mediaType": "application/vnd.oci.image.index.v1+json",
+			"digest":    "sha256:abc",
+		}})
+	})
+	build := &config.BuildSpec{
+		Cwd:         dir,
+		AuthConfigs: map[string]config.RegistryAuthSpec{"registry.example.com": {Username: "ci", Password: "secret"}},
+	}
+	svc := newFakeDaemonService(t, handler, &config.Config{
+		BaseDir: dir,
+		Aliases: map[string]config.Alias{"dev": {Image: config.ImageSpec{Build: build}}},
+	})
+
+	lock, err := svc.Lock(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Lock error: %v", err)
+	}
+	if bases := lock.Aliases["dev"].Bases; len(bases) != 1 || bases[0].Digest != "sha256:abc" {
+		t.Fatalf("unexpected bases: %+v", lock.Aliases["dev"])
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth)
+	var payload map[string]string
+	if err != nil || json.Unmarshal(decoded, &payload) != nil || payload["username"] != "ci" {
+		t.Fatalf("expected auth_configs credentials for the base image, got %q", auth)
+	}
+}
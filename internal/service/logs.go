@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+)
+
+// LogsOptions selects which container output Logs prints.
+type LogsOptions struct {
+	Follow     bool
+	Tail       string
+	Timestamps bool
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// Logs copies the output of the target container to opts.Stdout and opts.Stderr.
+func (s *Service) Logs(ctx context.Context, target Target, here bool, opts LogsOptions) error {
+	ctr, _, err := s.inspectTarget(ctx, target, here)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.cli.ContainerLogs(ctx, ctr.Container.ID, client.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	if ctr.Container.Config != nil && ctr.Container.Config.Tty {
+		_, err = io.Copy(opts.Stdout, reader)
+	} else {
+		_, err = stdcopy.StdCopy(opts.Stdout, opts.Stderr, reader)
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
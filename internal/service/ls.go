@@ -2,21 +2,25 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
 )
 
 type AliasStatus struct {
 	Name             string
+	Instance         string
+	Snapshot         bool
 	Kind             ImageKind
 	ImageRef         string
 	ImagePresent     bool
 	ContainerName    string
 	ContainerPresent bool
 	ContainerStatus  string
+	Bundle           BundleState
 }
 
 func (s *Service) ListStatuses(ctx context.Context) ([]AliasStatus, error) {
@@ -26,45 +30,120 @@ func (s *Service) ListStatuses(ctx context.Context) ([]AliasStatus, error) {
 	}
 	sort.Strings(names)
 
+	instances, err := s.instanceContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.snapshotImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := LoadedBundles(BundleStatePath())
+	if err != nil {
+		return nil, err
+	}
+
 	out := make([]AliasStatus, 0, len(names))
 	for _, name := range names {
-		status, err := s.aliasStatus(ctx, name)
-		if err != nil {
-			return nil, err
+		status, imageID, statusErr := s.aliasStatus(ctx, name)
+		if statusErr != nil {
+			return nil, statusErr
 		}
+		recorded, ok := bundles[name]
+		status.Bundle = CompareBundle(recorded, ok, imageID)
 		out = append(out, status)
+		for _, ctr := range instances[name] {
+			instance := status
+			instance.Instance = ctr.Labels[instanceLabel]
+			instance.ContainerName = containerSummaryName(ctr)
+			instance.ContainerPresent = true
+			instance.ContainerStatus = string(ctr.State)
+			out = append(out, instance)
+		}
+		for _, snap := range snapshots[name] {
+			out = append(out, AliasStatus{
+				Name:         name,
+				Snapshot:     true,
+				Kind:         status.Kind,
+				ImageRef:     snap.Ref,
+				ImagePresent: true,
+			})
+		}
 	}
 
 	return out, nil
 }
 
-func (s *Service) aliasStatus(ctx context.Context, name string) (AliasStatus, error) {
+// instanceContainers returns the alias@instance containers grouped by alias and
+// sorted by instance name.
+func (s *Service) instanceContainers(ctx context.Context) (map[string][]container.Summary, error) {
+	list, err := s.cli.ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: make(client.Filters).Add("label", instanceLabel),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := map[string][]container.Summary{}
+	for _, ctr := range list.Items {
+		// Snapshots clear the instance label, so containers created from them inherit it empty.
+		if ctr.Labels[instanceLabel] == "" {
+			continue
+		}
+		alias := ctr.Labels[aliasLabel]
+		grouped[alias] = append(grouped[alias], ctr)
+	}
+	for _, items := range grouped {
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Labels[instanceLabel] != items[j].Labels[instanceLabel] {
+				return items[i].Labels[instanceLabel] < items[j].Labels[instanceLabel]
+			}
+			return containerSummaryName(items[i]) < containerSummaryName(items[j])
+		})
+	}
+	return grouped, nil
+}
+
+func containerSummaryName(ctr container.Summary) string {
+	if len(ctr.Names) == 0 {
+		return ctr.ID
+	}
+	return strings.TrimPrefix(ctr.Names[0], "/")
+}
+
+// aliasStatus reports the alias image and container state, plus the local image ID
+// (empty when the image is missing).
+func (s *Service) aliasStatus(ctx context.Context, name string) (AliasStatus, string, error) {
 	info, err := s.AliasInfo(name)
 	if err != nil {
-		return AliasStatus{}, err
+		return AliasStatus{}, "", err
 	}
 
 	imageRef := resolveImageRef(info)
-	imagePresent, err := s.imageExists(ctx, imageRef)
+	imageID, err := s.imageID(ctx, imageRef)
 	if err != nil {
-		return AliasStatus{}, err
+		return AliasStatus{}, "", err
 	}
 
-	containerName := s.resolveContainerName(name)
+	containerName, _, err := s.containerTarget(Target{Alias: name}, false)
+	if err != nil {
+		return AliasStatus{}, "", err
+	}
 	containerPresent, containerStatus, err := s.containerInfo(ctx, containerName)
 	if err != nil {
-		return AliasStatus{}, err
+		return AliasStatus{}, "", err
 	}
 
 	return AliasStatus{
 		Name:             info.Name,
 		Kind:             info.Kind,
 		ImageRef:         imageRef,
-		ImagePresent:     imagePresent,
+		ImagePresent:     imageID != "",
 		ContainerName:    containerName,
 		ContainerPresent: containerPresent,
 		ContainerStatus:  containerStatus,
-	}, nil
+	}, imageID, nil
 }
 
 func resolveImageRef(info AliasInfo) string {
@@ -75,20 +154,24 @@ func resolveImageRef(info AliasInfo) string {
 }
 
 func (s *Service) imageExists(ctx context.Context, ref string) (bool, error) {
-	if _, err := s.cli.ImageInspect(ctx, ref); err != nil {
-		if errdefs.IsNotFound(err) {
-			return false, nil
-		}
+	id, err := s.imageID(ctx, ref)
+	if err != nil {
 		return false, err
 	}
-	return true, nil
+	s.log.Debug("image exists check", "ref", ref, "exists", id != "", "id", id)
+	return id != "", nil
 }
 
-func (s *Service) resolveContainerName(alias string) string {
-	if name := s.cfg.Aliases[alias].Run.Name; name != "" {
-		return name
+// imageID returns the local ID of ref, or an empty string when it is missing.
+func (s *Service) imageID(ctx context.Context, ref string) (string, error) {
+	inspect, err := s.cli.ImageInspect(ctx, ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
 	}
-	return fmt.Sprintf("cradle-%s", alias)
+	return inspect.ID, nil
 }
 
 func (s *Service) containerInfo(ctx context.Context, name string) (bool, string, error) {
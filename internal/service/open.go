@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrNoURLOpener is returned by OpenURL when the host has no known URL opener.
+var ErrNoURLOpener = errors.New("no URL opener found; set $BROWSER")
+
+// URLOpener returns the host command used to open URLs on goos. $BROWSER wins when set.
+func URLOpener(goos string) []string {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return []string{browser}
+	}
+	switch goos {
+	case "darwin":
+		return []string{"open"}
+	case "windows":
+		return []string{"rundll32", "url.dll,FileProtocolHandler"}
+	default:
+		return []string{"xdg-open"}
+	}
+}
+
+// OpenURL passes url to the host's URL opener.
+func OpenURL(ctx context.Context, url string) error {
+	opener := URLOpener(runtime.GOOS)
+	path, err := exec.LookPath(opener[0])
+	if err != nil {
+		return ErrNoURLOpener
+	}
+	args := append(append([]string{}, opener[1:]...), url)
+	//nolint:gosec // the opener is a fixed host command or the user's $BROWSER
+	if runErr := exec.CommandContext(ctx, path, args...).Run(); runErr != nil {
+		return fmt.Errorf("open %s: %w", url, runErr)
+	}
+	return nil
+}
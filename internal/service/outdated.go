@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/containerd/errdefs"
+)
+
+// UpdateStatus describes how a local pull image compares to its registry manifest.
+type UpdateStatus string
+
+const (
+	UpdateCurrent   UpdateStatus = "current"
+	UpdateAvailable UpdateStatus = "outdated"
+	UpdateMissing   UpdateStatus = "missing"
+	UpdateUnknown   UpdateStatus = "unknown"
+	// UpdateLocal marks a locally built or loaded image, which has no registry
+	// digest to compare.
+	UpdateLocal UpdateStatus = "local"
+)
+
+// ImageUpdate is the registry check result for one pull alias.
+type ImageUpdate struct {
+	Alias        string       `json:"alias"`
+	Ref          string       `json:"ref"`
+	LocalDigest  string       `json:"local_digest,omitempty"`
+	RemoteDigest string       `json:"remote_digest,omitempty"`
+	Status       UpdateStatus `json:"status"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// Outdated compares the local RepoDigests of every pull alias with the registry's
+// manifest digest. Nothing is pulled. Registry errors are reported per alias.
+func (s *Service) Outdated(ctx context.Context) ([]ImageUpdate, error) {
+	var out []ImageUpdate
+	for _, info := range s.ListAliases() {
+		if info.Kind != ImagePull {
+			continue
+		}
+		update, err := s.checkUpdate(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, update)
+	}
+	return out, nil
+}
+
+func (s *Service) checkUpdate(ctx context.Context, info AliasInfo) (ImageUpdate, error) {
+	update := ImageUpdate{Alias: info.Name, Ref: info.Ref}
+
+	options, err := PullOptionsFromSpec(s.cfg.Aliases[info.Name].Image.Pull)
+	if err != nil {
+		return update, err
+	}
+	remote, err := s.resolveDigest(ctx, info.Ref, options.RegistryAuth)
+	if err != nil {
+		update.Status = UpdateUnknown
+		update.Error = err.Error()
+		return update, nil
+	}
+	update.RemoteDigest = remote
+
+	local, err := s.localDigests(ctx, info.Ref)
+	if err != nil {
+		return update, err
+	}
+	update.Status = CompareDigests(local, remote)
+	if len(local) > 0 {
+		update.LocalDigest = local[0]
+	}
+	if update.Status == UpdateCurrent {
+		update.LocalDigest = remote
+	}
+	return update, nil
+}
+
+// localDigests returns the manifest digests recorded for the local image of ref.
+func (s *Service) localDigests(ctx context.Context, ref string) ([]string, error) {
+	inspect, err := s.cli.ImageInspect(ctx, ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	digests := make([]string, 0, len(inspect.RepoDigests))
+	for _, repoDigest := range inspect.RepoDigests {
+		if _, digest, ok := strings.Cut(repoDigest, "@"); ok {
+			digests = append(digests, digest)
+		}
+	}
+	return digests, nil
+}
+
+// CompareDigests reports whether remote is among the local digests. A nil local
+// slice means the image is not present; an empty one means it has no registry digest.
+func CompareDigests(local []string, remote string) UpdateStatus {
+	if local == nil {
+		return UpdateMissing
+	}
+	if len(local) == 0 {
+		return UpdateLocal
+	}
+	for _, digest := range local {
+		if digest == remote {
+			return UpdateCurrent
+		}
+	}
+	return UpdateAvailable
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+// fakeRegistry stands in for the daemon's image store and registry lookups.
+type fakeRegistry struct {
+	local  map[string][]string // ref -> RepoDigests
+	remote map[string]string   // ref -> manifest digest
+}
+
+func (f fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, _ := url.PathUnescape(r.URL.EscapedPath())
+	switch {
+	case strings.Contains(path, "/distribution/"):
+		ref := strings.TrimSuffix(path[strings.Index(path, "/distribution/")+len("/distribution/"):], "/json")
+		digest, ok := f.remote[ref]
+		if !ok {
+			http.Error(w, `{"message":"manifest unknown"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"Descriptor": map[string]any{"mediaType": "application/vnd.oci.image.index.v1+json", "digest": digest},
+		})
+	case strings.Contains(path, "/images/"):
+		ref := strings.TrimSuffix(path[strings.Index(path, "/images/")+len("/images/"):], "/json")
+		digests, ok := f.local[ref]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such image: ` + ref + `"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"Id": "sha256:local", "RepoDigests": digests})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	registry := fakeRegistry{
+		local: map[string][]string{
+			"ubuntu:24.04": {"ubuntu@sha256:aaa"},
+			"nginx:1.27":   {"nginx@sha256:old"},
+			"node:22":      {},
+		},
+		remote: map[string]string{
+			"ubuntu:24.04": "sha256:aaa",
+			"nginx:1.27":   "sha256:new",
+			"redis:7":      "sha256:ccc",
+			"node:22":      "sha256:ddd",
+		},
+	}
+	pull := func(ref string) config.Alias {
+		return config.Alias{Image: config.ImageSpec{Pull: &config.PullSpec{Ref: ref}}}
+	}
+	svc := newFakeDaemonService(t, registry, &config.Config{Aliases: map[string]config.Alias{
+		"base":    pull("ubuntu:24.04"),
+		"web":     pull("nginx:1.27"),
+		"cache":   pull("redis:7"),
+		"node":    pull("node:22"),
+		"private": pull("example.com/team/app:1"),
+		"dev":     {Image: config.ImageSpec{Build: &config.BuildSpec{Cwd: t.TempDir()}}},
+	}})
+
+	items, err := svc.Outdated(context.Background())
+	if err != nil {
+		t.Fatalf("Outdated error: %v", err)
+	}
+	got := map[string]service.ImageUpdate{}
+	for _, item := range items {
+		got[item.Alias] = item
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected only pull aliases, got %+v", items)
+	}
+	if got["base"].Status != service.UpdateCurrent {
+		t.Fatalf("expected base current, got %+v", got["base"])
+	}
+	if web := got["web"]; web.Status != service.UpdateAvailable || web.LocalDigest != "sha256:old" ||
+		web.RemoteDigest != "sha256:new" {
+		t.Fatalf("expected web outdated, got %+v", web)
+	}
+	if node := got["node"]; node.Status != service.UpdateLocal || node.LocalDigest != "" {
+		t.Fatalf("expected node local, got %+v", node)
+	}
+	if got["cache"].Status != service.UpdateMissing {
+		t.Fatalf("expected cache missing, got %+v", got["cache"])
+	}
+	if private := got["private"]; private.Status != service.UpdateUnknown || private.Error == "" {
+		t.Fatalf("expected private registry error, got %+v", private)
+	}
+}
+
+func TestCompareDigests(t *testing.T) {
+	if got := service.CompareDigests(nil, "sha256:a"); got != service.UpdateMissing {
+		t.Fatalf("expected missing, got %q", got)
+	}
+	if got := service.CompareDigests([]string{"sha256:b", "sha256:a"}, "sha256:a"); got != service.UpdateCurrent {
+		t.Fatalf("expected current, got %q", got)
+	}
+	if got := service.CompareDigests([]string{"sha256:b"}, "sha256:a"); got != service.UpdateAvailable {
+		t.Fatalf("expected outdated, got %q", got)
+	}
+	if got := service.CompareDigests([]string{}, "sha256:a"); got != service.UpdateLocal {
+		t.Fatalf("expected local, got %q", got)
+	}
+}
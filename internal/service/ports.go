@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	mobynet "github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+)
+
+// autoHostPort in a port spec ("auto:80") lets Docker pick a free host port.
+const autoHostPort = "auto"
+
+// PublishedPort is a container port bound on the host after start.
+type PublishedPort struct {
+	ContainerPort string `json:"container_port"`
+	Protocol      string `json:"protocol"`
+	HostIP        string `json:"host_ip"`
+	HostPort      string `json:"host_port"`
+	URL           string `json:"url,omitempty"`
+}
+
+// CheckHostPorts reports fixed host ports in bindings that are already in use on
+// this host. Auto-assigned ports ("", "0") and port ranges are skipped.
+func CheckHostPorts(bindings mobynet.PortMap) error {
+	var errs []error
+	for port, list := range bindings {
+		for _, binding := range list {
+			if binding.HostPort == "" || binding.HostPort == "0" || strings.Contains(binding.HostPort, "-") {
+				continue
+			}
+			host := ""
+			if binding.HostIP.IsValid() {
+				host = binding.HostIP.String()
+			}
+			if err := probeHostPort(string(port.Proto()), net.JoinHostPort(host, binding.HostPort)); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"host port %s/%s for container port %s is already in use; choose another port or use auto:%d",
+					binding.HostPort, port.Proto(), port, port.Num(),
+				))
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// PublishedPorts flattens a container's port map into a sorted list.
+func PublishedPorts(ports mobynet.PortMap) []PublishedPort {
+	var out []PublishedPort
+	for port, list := range ports {
+		for _, binding := range list {
+			if binding.HostPort == "" {
+				continue
+			}
+			published := PublishedPort{
+				ContainerPort: port.Port(),
+				Protocol:      string(port.Proto()),
+				HostPort:      binding.HostPort,
+			}
+			if binding.HostIP.IsValid() {
+				published.HostIP = binding.HostIP.String()
+			}
+			if port.Proto() == mobynet.TCP {
+				published.URL = portURL("http", published.HostIP, binding.HostPort, "")
+			}
+			out = append(out, published)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ContainerPort != out[j].ContainerPort {
+			return out[i].ContainerPort < out[j].ContainerPort
+		}
+		if out[i].Protocol != out[j].Protocol {
+			return out[i].Protocol < out[j].Protocol
+		}
+		return out[i].HostIP < out[j].HostIP
+	})
+	return out
+}
+
+// ApplyPortURLs rewrites the URL of each published tcp port that has a run.urls entry,
+// keyed by container port ("8443" or "8443/tcp").
+func ApplyPortURLs(ports []PublishedPort, urls map[string]config.PortURLSpec) []PublishedPort {
+	for i, port := range ports {
+		if port.Protocol != string(mobynet.TCP) {
+			continue
+		}
+		spec, ok := urls[port.ContainerPort]
+		if !ok {
+			spec, ok = urls[port.ContainerPort+"/"+port.Protocol]
+		}
+		if !ok {
+			continue
+		}
+		scheme := spec.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		ports[i].URL = portURL(scheme, port.HostIP, port.HostPort, spec.Path)
+	}
+	return ports
+}
+
+// portURL builds a browser URL for a host binding; wildcard addresses become localhost.
+func portURL(scheme, hostIP, hostPort, path string) string {
+	host := "localhost"
+	if addr, err := netip.ParseAddr(hostIP); err == nil && !addr.IsUnspecified() {
+		host = hostIP
+	}
+	return scheme + "://" + net.JoinHostPort(host, hostPort) + path
+}
+
+// SelectURL returns the URL for containerPort, or the first published URL when
+// containerPort is empty.
+func SelectURL(ports []PublishedPort, containerPort string) (string, error) {
+	want, _, _ := strings.Cut(containerPort, "/")
+	for _, port := range ports {
+		if port.URL == "" || (want != "" && port.ContainerPort != want) {
+			continue
+		}
+		return port.URL, nil
+	}
+	if want != "" {
+		return "", fmt.Errorf("container port %s is not published over tcp", containerPort)
+	}
+	return "", errors.New("container has no published tcp ports")
+}
+
+// Ports returns the published ports of the alias container with run.urls applied.
+func (s *Service) Ports(ctx context.Context, t Target, here bool) ([]PublishedPort, error) {
+	ctr, run, err := s.inspectTarget(ctx, t, here)
+	if err != nil {
+		return nil, err
+	}
+	if ctr.Container.NetworkSettings == nil {
+		return nil, nil
+	}
+	return ApplyPortURLs(PublishedPorts(ctr.Container.NetworkSettings.Ports), run.URLs), nil
+}
+
+func probeHostPort(proto, address string) error {
+	if proto == string(mobynet.UDP) {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return ln.Close()
+}
+
+// localDaemon reports whether the Docker daemon shares this host's network, so that
+// host port checks are meaningful.
+func (s *Service) localDaemon() bool {
+	host := s.cli.DaemonHost()
+	return strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+func (s *Service) publishedPorts(ctx context.Context, id string) []PublishedPort {
+	ctr, err := s.cli.ContainerInspect(ctx, id, client.ContainerInspectOptions{})
+	if err != nil || ctr.Container.NetworkSettings == nil {
+		return nil
+	}
+	return PublishedPorts(ctr.Container.NetworkSettings.Ports)
+}
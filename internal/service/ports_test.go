@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+
+	mobynet "github.com/moby/moby/api/types/network"
+)
+
+func TestCheckHostPortsInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen unavailable: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	busy := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+	port, _ := mobynet.ParsePort("8080")
+	bindings := mobynet.PortMap{port: {{HostIP: netip.MustParseAddr("127.0.0.1"), HostPort: busy}}}
+	err = service.CheckHostPorts(bindings)
+	if err == nil {
+		t.Fatalf("expected conflict error")
+	}
+	if !strings.Contains(err.Error(), busy) || !strings.Contains(err.Error(), "auto:8080") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckHostPortsSkipsAuto(t *testing.T) {
+	port, _ := mobynet.ParsePort("80")
+	bindings := mobynet.PortMap{port: {{HostPort: ""}, {HostPort: "0"}}}
+	if err := service.CheckHostPorts(bindings); err != nil {
+		t.Fatalf("expected auto ports to be skipped, got %v", err)
+	}
+}
+
+func TestPublishedPorts(t *testing.T) {
+	tcp, _ := mobynet.ParsePort("80")
+	udp, _ := mobynet.ParsePort("53/udp")
+	ports := service.PublishedPorts(mobynet.PortMap{
+		tcp: {
+			{HostIP: netip.MustParseAddr("0.0.0.0"), HostPort: "49153"},
+			{HostIP: netip.MustParseAddr("127.0.0.1"), HostPort: "49154"},
+		},
+		udp: {{HostIP: netip.MustParseAddr("0.0.0.0"), HostPort: "5353"}},
+	})
+	if len(ports) != 3 {
+		t.Fatalf("expected 3 ports, got %+v", ports)
+	}
+	if ports[0].ContainerPort != "53" || ports[0].URL != "" {
+		t.Fatalf("expected udp port without url first, got %+v", ports[0])
+	}
+	if ports[1].URL != "http://localhost:49153" {
+		t.Fatalf("unexpected url for unspecified host: %+v", ports[1])
+	}
+	if ports[2].URL != "http://127.0.0.1:49154" {
+		t.Fatalf("unexpected url for loopback host: %+v", ports[2])
+	}
+}
+
+func TestApplyPortURLsAndSelectURL(t *testing.T) {
+	ports := []service.PublishedPort{
+		{ContainerPort: "53", Protocol: "udp", HostIP: "0.0.0.0", HostPort: "5353"},
+		{ContainerPort: "3000", Protocol: "tcp", HostIP: "0.0.0.0", HostPort: "3000", URL: "http://localhost:3000"},
+		{ContainerPort: "8443", Protocol: "tcp", HostIP: "127.0.0.1", HostPort: "8443", URL: "http://127.0.0.1:8443"},
+	}
+	ports = service.ApplyPortURLs(ports, map[string]config.PortURLSpec{
+		"8443/tcp": {Scheme: "https", Path: "/"},
+		"53":       {Scheme: "dns"},
+	})
+	if ports[2].URL != "https://127.0.0.1:8443/" {
+		t.Fatalf("unexpected url: %q", ports[2].URL)
+	}
+	if ports[0].URL != "" {
+		t.Fatalf("expected udp port to stay without url, got %q", ports[0].URL)
+	}
+
+	url, err := service.SelectURL(ports, "")
+	if err != nil || url != "http://localhost:3000" {
+		t.Fatalf("unexpected default url %q: %v", url, err)
+	}
+	url, err = service.SelectURL(ports, "8443/tcp")
+	if err != nil || url != "https://127.0.0.1:8443/" {
+		t.Fatalf("unexpected url for 8443 %q: %v", url, err)
+	}
+	if _, err = service.SelectURL(ports, "53"); err == nil {
+		t.Fatalf("expected error for udp port")
+	}
+	if _, err = service.SelectURL(nil, ""); err == nil {
+		t.Fatalf("expected error without ports")
+	}
+}
+
+func TestURLOpener(t *testing.T) {
+	t.Setenv("BROWSER", "")
+	if got := service.URLOpener("darwin"); got[0] != "open" {
+		t.Fatalf("unexpected darwin opener: %v", got)
+	}
+	if got := service.URLOpener("linux"); got[0] != "xdg-open" {
+		t.Fatalf("unexpected linux opener: %v", got)
+	}
+	t.Setenv("BROWSER", "firefox")
+	if got := service.URLOpener("linux"); len(got) != 1 || got[0] != "firefox" {
+		t.Fatalf("expected $BROWSER to win, got %v", got)
+	}
+}
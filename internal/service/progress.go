@@ -0,0 +1,476 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+	controlapi "github.com/moby/buildkit/api/services/control"
+)
+
+const (
+	// liveLogLines is how many log lines the live view keeps under a running step.
+	liveLogLines     = 5
+	liveTickInterval = 100 * time.Millisecond
+	spinnerFrames    = "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏"
+)
+
+// progressView shows the BuildKit status updates of one build.
+type progressView interface {
+	update(sr *controlapi.StatusResponse) error
+	// print writes output that is not part of the progress, such as pull status.
+	print(s string) error
+	finish() error
+}
+
+// vertexState is what is known about one BuildKit vertex. Durations come from
+// daemon timestamps; seen is the local time it started, for elapsed time while
+// it runs.
+type vertexState struct {
+	index     int
+	name      string
+	started   *time.Time
+	completed *time.Time
+	seen      time.Time
+	cached    bool
+	err       string
+	statuses  []*controlapi.VertexStatus // latest per status ID, in order of appearance
+	logs      []string                   // tail of the vertex output
+}
+
+func (v *vertexState) duration() time.Duration {
+	if v.started == nil || v.completed == nil {
+		return 0
+	}
+	return v.completed.Sub(*v.started)
+}
+
+func (v *vertexState) setStatus(st *controlapi.VertexStatus) {
+	for i, existing := range v.statuses {
+		if existing.GetID() == st.GetID() {
+			v.statuses[i] = st
+			return
+		}
+	}
+	v.statuses = append(v.statuses, st)
+}
+
+// addLogs appends the lines of msg, keeping at most tail lines. Carriage returns
+// redraw a line in place, so only the text after the last one is kept.
+func (v *vertexState) addLogs(msg []byte, tail int) {
+	v.logs = append(v.logs, splitLogLines(msg)...)
+	if len(v.logs) > tail {
+		v.logs = slices.Delete(v.logs, 0, len(v.logs)-tail)
+	}
+}
+
+func splitLogLines(msg []byte) []string {
+	var lines []string
+	for line := range strings.SplitSeq(strings.TrimRight(string(msg), "\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// buildProgress merges BuildKit status updates into vertex state keyed by digest.
+// Vertices are numbered in the order they first appear.
+type buildProgress struct {
+	vertices map[string]*vertexState
+	order    []*vertexState
+	start    time.Time
+	now      func() time.Time
+}
+
+func newBuildProgress() *buildProgress {
+	return &buildProgress{vertices: map[string]*vertexState{}, now: time.Now}
+}
+
+// update merges sr, keeping up to logTail output lines per vertex.
+func (p *buildProgress) update(sr *controlapi.StatusResponse, logTail int) {
+	now := p.now()
+	if p.start.IsZero() {
+		p.start = now
+	}
+	for _, vtx := range sr.GetVertexes() {
+		v := p.vertex(vtx.GetDigest())
+		if name := strings.TrimSpace(vtx.GetName()); name != "" {
+			v.name = name
+		}
+		if vtx.GetStarted() != nil {
+			started := vtx.GetStarted().AsTime()
+			v.started = &started
+			if v.seen.IsZero() {
+				v.seen = now
+			}
+		}
+		if vtx.GetCompleted() != nil {
+			completed := vtx.GetCompleted().AsTime()
+			v.completed = &completed
+		}
+		v.cached = vtx.GetCached()
+		v.err = strings.TrimSpace(vtx.GetError())
+	}
+	for _, st := range sr.GetStatuses() {
+		p.vertex(st.GetVertex()).setStatus(st)
+	}
+	for _, log := range sr.GetLogs() {
+		p.vertex(log.GetVertex()).addLogs(log.GetMsg(), logTail)
+	}
+}
+
+func (p *buildProgress) vertex(digest string) *vertexState {
+	v, ok := p.vertices[digest]
+	if !ok {
+		v = &vertexState{index: len(p.order) + 1}
+		p.vertices[digest] = v
+		p.order = append(p.order, v)
+	}
+	return v
+}
+
+// counts returns how many named vertices have finished and how many there are.
+func (p *buildProgress) counts() (int, int) {
+	done, total := 0, 0
+	for _, v := range p.order {
+		if v.name == "" {
+			continue
+		}
+		total++
+		if v.completed != nil {
+			done++
+		}
+	}
+	return done, total
+}
+
+// plainProgress prints every step once, like `docker buildx build --progress=plain`:
+// the step name when it starts, its output as it arrives, then CACHED, DONE with
+// the duration, or ERROR.
+type plainProgress struct {
+	w        io.Writer
+	model    *buildProgress
+	headers  map[*vertexState]bool
+	finished map[*vertexState]bool
+	statuses map[string]bool // vertex digest + status ID whose completion was printed
+}
+
+func newPlainProgress(w io.Writer) *plainProgress {
+	return &plainProgress{
+		w:        w,
+		model:    newBuildProgress(),
+		headers:  map[*vertexState]bool{},
+		finished: map[*vertexState]bool{},
+		statuses: map[string]bool{},
+	}
+}
+
+func (pp *plainProgress) update(sr *controlapi.StatusResponse) error {
+	pp.model.update(sr, 0)
+	var b strings.Builder
+	for _, vtx := range sr.GetVertexes() {
+		pp.writeVertex(&b, pp.model.vertices[vtx.GetDigest()])
+	}
+	for _, st := range sr.GetStatuses() {
+		key := st.GetVertex() + "|" + st.GetID()
+		if st.GetCompleted() == nil || pp.statuses[key] {
+			continue
+		}
+		pp.statuses[key] = true
+		v := pp.model.vertices[st.GetVertex()]
+		pp.writeHeader(&b, v)
+		fmt.Fprintf(&b, "#%d %s done\n", v.index, strings.Join(FilterEmpty([]string{st.GetID(), statusSize(st)}), " "))
+	}
+	for _, log := range sr.GetLogs() {
+		v := pp.model.vertices[log.GetVertex()]
+		pp.writeHeader(&b, v)
+		offset := 0.0
+		if v.started != nil && log.GetTimestamp() != nil {
+			offset = log.GetTimestamp().AsTime().Sub(*v.started).Seconds()
+		}
+		for _, line := range splitLogLines(log.GetMsg()) {
+			fmt.Fprintf(&b, "#%d %.3f %s\n", v.index, offset, line)
+		}
+	}
+	for _, line := range warningLines(sr.GetWarnings()) {
+		b.WriteString(line + "\n")
+	}
+	return writeString(pp.w, b.String())
+}
+
+func (pp *plainProgress) writeHeader(b *strings.Builder, v *vertexState) {
+	if pp.headers[v] || v.name == "" {
+		return
+	}
+	pp.headers[v] = true
+	fmt.Fprintf(b, "#%d %s\n", v.index, v.name)
+}
+
+func (pp *plainProgress) writeVertex(b *strings.Builder, v *vertexState) {
+	if v.started == nil && v.completed == nil {
+		return
+	}
+	pp.writeHeader(b, v)
+	if v.completed == nil || pp.finished[v] || v.name == "" {
+		return
+	}
+	pp.finished[v] = true
+	switch {
+	case v.err != "":
+		fmt.Fprintf(b, "#%d ERROR: %s\n", v.index, v.err)
+	case v.cached:
+		fmt.Fprintf(b, "#%d CACHED\n", v.index)
+	default:
+		fmt.Fprintf(b, "#%d DONE %.1fs\n", v.index, v.duration().Seconds())
+	}
+}
+
+func (pp *plainProgress) print(s string) error {
+	return writeString(pp.w, s)
+}
+
+func (pp *plainProgress) finish() error {
+	return nil
+}
+
+// statusSize formats the byte progress of a status, e.g. "12.3MB / 45.6MB".
+func statusSize(st *controlapi.VertexStatus) string {
+	switch {
+	case st.GetTotal() > 0:
+		return units.HumanSize(float64(st.GetCurrent())) + " / " + units.HumanSize(float64(st.GetTotal()))
+	case st.GetCurrent() > 0:
+		return units.HumanSize(float64(st.GetCurrent()))
+	default:
+		return ""
+	}
+}
+
+// liveProgress redraws the build in place on a terminal. Finished steps collapse
+// to one line with their duration; running steps show elapsed time, transfers and
+// their last output lines. A ticker keeps the elapsed times moving between updates.
+type liveProgress struct {
+	mu     sync.Mutex
+	region liveRegion
+	model  *buildProgress
+	frame  int // spinner frame
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newLiveProgress(w io.Writer, size func() (int, int)) *liveProgress {
+	return &liveProgress{region: liveRegion{w: w, size: size}, model: newBuildProgress()}
+}
+
+func (lp *liveProgress) update(sr *controlapi.StatusResponse) error {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	lp.model.update(sr, liveLogLines)
+	if lp.stop == nil {
+		lp.stop = make(chan struct{})
+		lp.stopped = make(chan struct{})
+		go lp.tick()
+	}
+	return lp.redraw("")
+}
+
+func (lp *liveProgress) print(s string) error {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if lp.region.lines == 0 {
+		return writeString(lp.region.w, s)
+	}
+	return lp.redraw(s)
+}
+
+// finish stops the ticker and leaves the final frame on screen.
+func (lp *liveProgress) finish() error {
+	if lp.stop != nil {
+		close(lp.stop)
+		<-lp.stopped
+	}
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if lp.region.lines == 0 {
+		return nil
+	}
+	return lp.redraw("")
+}
+
+func (lp *liveProgress) tick() {
+	defer close(lp.stopped)
+	ticker := time.NewTicker(liveTickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lp.stop:
+			return
+		case <-ticker.C:
+			lp.mu.Lock()
+			lp.frame++
+			_ = lp.redraw("")
+			lp.mu.Unlock()
+		}
+	}
+}
+
+// redraw draws the current frame, writing above first.
+func (lp *liveProgress) redraw(above string) error {
+	return lp.region.draw(above, func(maxLines int) []string {
+		return liveFrame(lp.model, lp.model.now(), lp.frame, maxLines)
+	})
+}
+
+// liveRegion is the block of lines at the bottom of a terminal that a live view
+// redraws in place.
+type liveRegion struct {
+	w     io.Writer
+	size  func() (int, int)
+	lines int // lines drawn by the last frame
+}
+
+// draw erases the previous frame, writes above (if any) and draws the lines frame
+// returns for the terminal height.
+func (r *liveRegion) draw(above string, frame func(maxLines int) []string) error {
+	var b strings.Builder
+	if r.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", r.lines)
+	}
+	b.WriteString("\r\x1b[J")
+	b.WriteString(above)
+	width, height := r.size()
+	lines := frame(height - 1)
+	for _, line := range lines {
+		b.WriteString(truncateLine(line, width-1) + colorReset + "\n")
+	}
+	r.lines = len(lines)
+	return writeString(r.w, b.String())
+}
+
+// liveFrame renders the model as at most maxLines lines. When steps do not fit,
+// the oldest finished ones fold into a count.
+func liveFrame(p *buildProgress, now time.Time, frame, maxLines int) []string {
+	done, total := p.counts()
+	header := fmt.Sprintf("[+] Building %.1fs (%d/%d)", now.Sub(p.start).Seconds(), done, total)
+	if total > 0 && done == total {
+		header = fmt.Sprintf("[+] Building %.1fs (%d/%d) FINISHED", lastCompleted(p).Seconds(), done, total)
+	}
+
+	var blocks [][]string
+	var finished []int // indexes into blocks of collapsible finished steps
+	for _, v := range p.order {
+		if v.name == "" || (v.started == nil && v.completed == nil) {
+			continue
+		}
+		if v.completed != nil && v.err == "" {
+			finished = append(finished, len(blocks))
+		}
+		blocks = append(blocks, vertexBlock(v, now, frame))
+	}
+
+	count := 1
+	for _, block := range blocks {
+		count += len(block)
+	}
+	folded := 0
+	for _, i := range finished {
+		if count <= maxLines {
+			break
+		}
+		blocks[i] = nil
+		folded++
+		count--
+	}
+
+	lines := []string{colorCyan + header}
+	if folded > 0 {
+		lines = append(lines, fmt.Sprintf("%s✔%s %d earlier steps finished", colorGreen, colorReset, folded))
+	}
+	for _, block := range blocks {
+		lines = append(lines, block...)
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = append(lines[:1], lines[len(lines)-maxLines+1:]...)
+	}
+	return lines
+}
+
+// lastCompleted is the build time measured by the daemon, from the first start to
+// the last completion.
+func lastCompleted(p *buildProgress) time.Duration {
+	var first, last time.Time
+	for _, v := range p.order {
+		if v.started != nil && (first.IsZero() || v.started.Before(first)) {
+			first = *v.started
+		}
+		if v.completed != nil && v.completed.After(last) {
+			last = *v.completed
+		}
+	}
+	if first.IsZero() {
+		return 0
+	}
+	return last.Sub(first)
+}
+
+func vertexBlock(v *vertexState, now time.Time, frame int) []string {
+	name := fmt.Sprintf("#%d %s", v.index, v.name)
+	switch {
+	case v.err != "":
+		lines := []string{fmt.Sprintf("%s✖ %s ERROR: %s", colorRed, name, v.err)}
+		for _, log := range v.logs {
+			lines = append(lines, colorDim+"  > "+log)
+		}
+		return lines
+	case v.completed != nil && v.cached:
+		return []string{fmt.Sprintf("%s✔%s %s %sCACHED", colorGreen, colorReset, name, colorDim)}
+	case v.completed != nil:
+		seconds := v.duration().Seconds()
+		return []string{fmt.Sprintf("%s✔%s %s %s%.1fs", colorGreen, colorReset, name, colorDim, seconds)}
+	}
+
+	spinner := []rune(spinnerFrames)
+	lines := []string{fmt.Sprintf("%s%c %s %.1fs", colorCyan, spinner[frame%len(spinner)], name,
+		now.Sub(v.seen).Seconds())}
+	for _, st := range v.statuses {
+		if st.GetCompleted() == nil {
+			lines = append(lines, colorDim+"  "+strings.Join(FilterEmpty([]string{st.GetID(), statusSize(st)}), " "))
+		}
+	}
+	for _, log := range v.logs {
+		lines = append(lines, colorDim+"  > "+log)
+	}
+	return lines
+}
+
+// truncateLine cuts line to width visible runes, skipping ANSI color sequences.
+func truncateLine(line string, width int) string {
+	if width <= 0 {
+		return line
+	}
+	var b strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range line {
+		switch {
+		case inEscape:
+			inEscape = r != 'm'
+		case r == '\x1b':
+			inEscape = true
+		default:
+			if visible == width {
+				return b.String()
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
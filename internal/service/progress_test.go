@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/pkg/protobuf"
+	"github.com/containerd/containerd/v2/pkg/protobuf/proto"
+	controlapi "github.com/moby/buildkit/api/services/control"
+
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+// traceMessage encodes sr as the moby.buildkit.trace message of a build stream.
+func traceMessage(t *testing.T, sr *controlapi.StatusResponse) string {
+	t.Helper()
+	raw, err := proto.Marshal(sr)
+	if err != nil {
+		t.Fatalf("marshal status: %v", err)
+	}
+	aux, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatalf("marshal aux: %v", err)
+	}
+	msg, err := json.Marshal(map[string]json.RawMessage{"id": json.RawMessage(`"moby.buildkit.trace"`), "aux": aux})
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	return string(msg) + "\n"
+}
+
+func TestRenderDockerJSONPlainProgress(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	at := func(seconds float64) *controlapi.Vertex {
+		offset := time.Duration(seconds * float64(time.Second))
+		return &controlapi.Vertex{Started: protobuf.ToTimestamp(start.Add(offset))}
+	}
+	from := &controlapi.Vertex{Digest: "sha256:from", Name: "[1/2] FROM docker.io/library/alpine", Cached: true,
+		Started: protobuf.ToTimestamp(start), Completed: protobuf.ToTimestamp(start)}
+	run := at(0.5)
+	run.Digest, run.Name = "sha256:run", "[2/2] RUN echo hi"
+	done := at(0.5)
+	done.Digest, done.Name = "sha256:run", "[2/2] RUN echo hi"
+	done.Completed = protobuf.ToTimestamp(start.Add(2 * time.Second))
+
+	var stream strings.Builder
+	stream.WriteString(traceMessage(t, &controlapi.StatusResponse{Vertexes: []*controlapi.Vertex{from, run}}))
+	// Repeated updates for a running step must not print it again.
+	stream.WriteString(traceMessage(t, &controlapi.StatusResponse{Vertexes: []*controlapi.Vertex{run}}))
+	stream.WriteString(traceMessage(t, &controlapi.StatusResponse{
+		Statuses: []*controlapi.VertexStatus{{ID: "extracting", Vertex: "sha256:run", Current: 2048, Total: 2048,
+			Completed: protobuf.ToTimestamp(start.Add(time.Second))}},
+		Logs: []*controlapi.VertexLog{{Vertex: "sha256:run", Msg: []byte("hi\nthere\n"),
+			Timestamp: protobuf.ToTimestamp(start.Add(750 * time.Millisecond))}},
+	}))
+	stream.WriteString(traceMessage(t, &controlapi.StatusResponse{Vertexes: []*controlapi.Vertex{done}}))
+	stream.WriteString(traceMessage(t, &controlapi.StatusResponse{Vertexes: []*controlapi.Vertex{done}}))
+
+	var out bytes.Buffer
+	if err := service.RenderDockerJSON(&out, strings.NewReader(stream.String())); err != nil {
+		t.Fatalf("RenderDockerJSON: %v", err)
+	}
+	want := strings.Join([]string{
+		"#1 [1/2] FROM docker.io/library/alpine",
+		"#1 CACHED",
+		"#2 [2/2] RUN echo hi",
+		"#2 extracting 2.048kB / 2.048kB done",
+		"#2 0.250 hi",
+		"#2 0.250 there",
+		"#2 DONE 1.5s",
+	}, "\n") + "\n"
+	if out.String() != want {
+		t.Fatalf("unexpected plain progress:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRenderDockerJSONPlainProgressError(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	failed := &controlapi.Vertex{Digest: "sha256:run", Name: "[2/2] RUN false", Error: "exit code: 1",
+		Started: protobuf.ToTimestamp(start), Completed: protobuf.ToTimestamp(start.Add(time.Second))}
+	stream := traceMessage(t, &controlapi.StatusResponse{Vertexes: []*controlapi.Vertex{failed}}) +
+		`{"errorDetail":{"message":"process did not complete"},"error":"process did not complete"}` + "\n"
+
+	var out bytes.Buffer
+	err := service.RenderDockerJSON(&out, strings.NewReader(stream))
+	if err == nil || err.Error() != "process did not complete" {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+	if out.String() != "#1 [2/2] RUN false\n#1 ERROR: exit code: 1\n" {
+		t.Fatalf("unexpected plain progress: %q", out.String())
+	}
+}
+
+func TestRenderDockerJSONPlainProgressWarnings(t *testing.T) {
+	stream := traceMessage(t, &controlapi.StatusResponse{Warnings: []*controlapi.VertexWarning{
+		{Short: []byte("FromAsCasing: 'as' and 'FROM' keywords' casing do not match"), Detail: [][]byte{
+			[]byte("see docs"),
+		}},
+	}})
+
+	var out bytes.Buffer
+	if err := service.RenderDockerJSON(&out, strings.NewReader(stream)); err != nil {
+		t.Fatalf("RenderDockerJSON: %v", err)
+	}
+	want := "warning: FromAsCasing: 'as' and 'FROM' keywords' casing do not match\nsee docs\n"
+	if out.String() != want {
+		t.Fatalf("unexpected plain progress: %q", out.String())
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+const projectHashLen = 8
+
+var projectNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ResolveProjectDir returns the directory mounted for mount_cwd: the nearest git root
+// above cwd when gitRoot is set, otherwise cwd itself. Symlinks are resolved so the
+// same project always maps to the same container.
+func ResolveProjectDir(cwd string, gitRoot bool) (string, error) {
+	dir, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if !gitRoot {
+		return dir, nil
+	}
+	for candidate := dir; ; {
+		if _, statErr := os.Stat(filepath.Join(candidate, ".git")); statErr == nil {
+			return candidate, nil
+		}
+		parent := filepath.Dir(candidate)
+		if parent == candidate {
+			return dir, nil
+		}
+		candidate = parent
+	}
+}
+
+// ProjectContainerName keys a container name to a project directory so that
+// different repositories get separate containers.
+func ProjectContainerName(base, dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	project := strings.Trim(projectNameUnsafe.ReplaceAllString(filepath.Base(dir), "-"), "-.")
+	if project == "" {
+		project = "root"
+	}
+	return fmt.Sprintf("%s-%s-%s", base, project, hex.EncodeToString(sum[:])[:projectHashLen])
+}
+
+// ApplyMountCwd binds dir at the mount_cwd target and makes it the working directory
+// unless run.work_dir is set.
+func ApplyMountCwd(run config.RunSpec, spec config.MountCwdSpec, dir string) config.RunSpec {
+	target := spec.Target
+	if target == "" {
+		target = config.DefaultMountCwdTarget
+	}
+	run.Volumes = append(append([]config.MountSpec{}, run.Volumes...), config.MountSpec{
+		Type:     "bind",
+		Source:   dir,
+		Target:   target,
+		ReadOnly: spec.ReadOnly,
+	})
+	if run.WorkDir == "" {
+		run.WorkDir = target
+	}
+	return run
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestResolveProjectDirFindsGitRoot(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("eval symlinks: %v", err)
+	}
+	if err = os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	sub := filepath.Join(root, "pkg", "sub")
+	if err = os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir sub: %v", err)
+	}
+
+	got, err := service.ResolveProjectDir(sub, true)
+	if err != nil {
+		t.Fatalf("ResolveProjectDir error: %v", err)
+	}
+	if got != root {
+		t.Fatalf("expected git root %q, got %q", root, got)
+	}
+
+	got, err = service.ResolveProjectDir(sub, false)
+	if err != nil {
+		t.Fatalf("ResolveProjectDir error: %v", err)
+	}
+	if got != sub {
+		t.Fatalf("expected cwd %q, got %q", sub, got)
+	}
+}
+
+func TestProjectContainerName(t *testing.T) {
+	a := service.ProjectContainerName("cradle-dev", "/src/app")
+	b := service.ProjectContainerName("cradle-dev", "/work/app")
+	if a == b {
+		t.Fatalf("expected distinct names for distinct projects, got %q", a)
+	}
+	if !strings.HasPrefix(a, "cradle-dev-app-") {
+		t.Fatalf("unexpected name: %q", a)
+	}
+	if a != service.ProjectContainerName("cradle-dev", "/src/app") {
+		t.Fatalf("expected stable name")
+	}
+	if got := service.ProjectContainerName("cradle-dev", "/src/my app!"); !strings.HasPrefix(got, "cradle-dev-my-app-") {
+		t.Fatalf("expected sanitized name, got %q", got)
+	}
+}
+
+func TestApplyMountCwd(t *testing.T) {
+	run := config.RunSpec{Volumes: []config.MountSpec{{Type: "volume", Source: "cache", Target: "/cache"}}}
+	got := service.ApplyMountCwd(run, config.MountCwdSpec{Enabled: true, Target: "/src", ReadOnly: true}, "/home/me/app")
+	if got.WorkDir != "/src" {
+		t.Fatalf("expected work_dir to follow target, got %q", got.WorkDir)
+	}
+	last := got.Volumes[len(got.Volumes)-1]
+	if len(got.Volumes) != 2 || last.Type != "bind" || last.Source != "/home/me/app" ||
+		last.Target != "/src" || !last.ReadOnly {
+		t.Fatalf("unexpected volumes: %+v", got.Volumes)
+	}
+	if len(run.Volumes) != 1 {
+		t.Fatalf("expected input volumes to be left untouched")
+	}
+
+	run.WorkDir = "/custom"
+	if got = service.ApplyMountCwd(run, config.MountCwdSpec{Enabled: true}, "/home/me/app"); got.WorkDir != "/custom" {
+		t.Fatalf("expected explicit work_dir to win, got %q", got.WorkDir)
+	}
+	if got.Volumes[len(got.Volumes)-1].Target != config.DefaultMountCwdTarget {
+		t.Fatalf("expected default target, got %+v", got.Volumes)
+	}
+}
+
+func TestRunFingerprintKeyedByProject(t *testing.T) {
+	spec := config.MountCwdSpec{Enabled: true}
+	first := service.ApplyMountCwd(config.RunSpec{}, spec, "/src/a")
+	second := service.ApplyMountCwd(config.RunSpec{}, spec, "/src/b")
+
+	fpA, err := service.RunFingerprint("dev", "cradle-dev", "img", "id", first, false, false, false)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	fpB, err := service.RunFingerprint("dev", "cradle-dev", "img", "id", second, false, false, false)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if fpA == fpB {
+		t.Fatalf("expected fingerprints to differ per project")
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+const percentScale = 100
+
+// LayerTotals sums the layers of a pull. Size, Downloaded and Extracted only count
+// layers whose size the daemon has reported; layers that already exist are done
+// without transferring anything.
+type LayerTotals struct {
+	Layers     int
+	Done       int
+	Size       int64
+	Downloaded int64
+	Extracted  int64
+}
+
+// Percent is the share of the download and extraction work that is done, or -1
+// when no layer size is known yet.
+func (t LayerTotals) Percent() int {
+	if t.Size <= 0 {
+		return -1
+	}
+	return int((t.Downloaded + t.Extracted) * percentScale / (2 * t.Size))
+}
+
+// String formats the totals as the summary line of a pull.
+func (t LayerTotals) String() string {
+	text := fmt.Sprintf("%d/%d layers", t.Done, t.Layers)
+	if t.Size <= 0 {
+		return text
+	}
+	size := units.HumanSize(float64(t.Size))
+	return fmt.Sprintf("%s, downloaded %s / %s, extracted %s / %s (%d%%)", text,
+		units.HumanSize(float64(t.Downloaded)), size, units.HumanSize(float64(t.Extracted)), size, t.Percent())
+}
+
+type layerState struct {
+	id         string
+	status     string
+	progress   string
+	size       int64
+	downloaded int64
+	extracted  int64
+	done       bool
+}
+
+// LayerProgress keeps the latest status of every layer in a pull, push or load
+// stream, in the order the layers first appear.
+type LayerProgress struct {
+	layers map[string]*layerState
+	order  []*layerState
+}
+
+func NewLayerProgress() *LayerProgress {
+	return &LayerProgress{layers: map[string]*layerState{}}
+}
+
+// Update records a status message for layer id. current and total are the byte
+// counts from the message progress detail.
+func (p *LayerProgress) Update(id, status, progress string, current, total int64) {
+	l, ok := p.layers[id]
+	if !ok {
+		l = &layerState{id: id}
+		p.layers[id] = l
+		p.order = append(p.order, l)
+	}
+	l.status, l.progress = status, progress
+	switch status {
+	case "Downloading":
+		if total > 0 {
+			l.size = total
+		}
+		l.downloaded = current
+	case "Verifying Checksum", "Download complete":
+		l.downloaded = l.size
+	case "Extracting":
+		if total > 0 && l.size == 0 {
+			l.size = total
+		}
+		l.downloaded, l.extracted = l.size, current
+	case "Pull complete":
+		l.downloaded, l.extracted, l.done = l.size, l.size, true
+	case "Already exists", "Pushed", "Layer already exists":
+		l.done = true
+	default:
+		l.done = l.done || strings.HasPrefix(status, "Mounted from")
+	}
+}
+
+// Totals sums the progress of all layers seen so far.
+func (p *LayerProgress) Totals() LayerTotals {
+	var t LayerTotals
+	for _, l := range p.order {
+		t.Layers++
+		if l.done {
+			t.Done++
+		}
+		t.Size += l.size
+		t.Downloaded += l.downloaded
+		t.Extracted += l.extracted
+	}
+	return t
+}
+
+// Len returns the number of layers seen so far.
+func (p *LayerProgress) Len() int {
+	return len(p.order)
+}
+
+// pullView draws one live line per layer under a totals line, replacing the line
+// per progress change that the plain mode prints.
+type pullView struct {
+	region liveRegion
+	layers *LayerProgress
+}
+
+func newPullView(w io.Writer, size func() (int, int)) *pullView {
+	return &pullView{region: liveRegion{w: w, size: size}, layers: NewLayerProgress()}
+}
+
+// active reports whether a frame is on screen, so other output has to go above it.
+func (v *pullView) active() bool {
+	return v.region.lines > 0
+}
+
+func (v *pullView) update(msg buildMessage) error {
+	v.layers.Update(msg.ID, msg.Status, msg.Progress, msg.ProgressDetail.Current, msg.ProgressDetail.Total)
+	return v.region.draw("", v.frame)
+}
+
+func (v *pullView) print(s string) error {
+	if !v.active() {
+		return writeString(v.region.w, s)
+	}
+	return v.region.draw(s, v.frame)
+}
+
+// frame renders the totals and the layer lines; when they do not fit in maxLines,
+// finished layers are dropped first.
+func (v *pullView) frame(maxLines int) []string {
+	lines := []string{colorCyan + "[+] " + v.layers.Totals().String()}
+	skip := v.layers.Len() + 1 - maxLines
+	for _, l := range v.layers.order {
+		if skip > 0 && l.done {
+			skip--
+			continue
+		}
+		color := colorYellow
+		if l.done {
+			color = colorGreen
+		}
+		lines = append(lines, color+strings.Join(FilterEmpty([]string{l.id + ":", l.status, l.progress}), " "))
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = append(lines[:1], lines[len(lines)-maxLines+1:]...)
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestLayerProgressTotals(t *testing.T) {
+	p := service.NewLayerProgress()
+	p.Update("aaaaaaaaaaaa", "Pulling fs layer", "", 0, 0)
+	p.Update("bbbbbbbbbbbb", "Already exists", "", 0, 0)
+	p.Update("cccccccccccc", "Downloading", "[=>   ] 500B/1kB", 500, 1000)
+	p.Update("aaaaaaaaaaaa", "Downloading", "[=>   ] 1kB/3kB", 1000, 3000)
+	p.Update("aaaaaaaaaaaa", "Download complete", "", 0, 0)
+	p.Update("aaaaaaaaaaaa", "Extracting", "[=>   ] 1.5kB/3kB", 1500, 3000)
+
+	got := p.Totals()
+	want := service.LayerTotals{Layers: 3, Done: 1, Size: 4000, Downloaded: 3500, Extracted: 1500}
+	if got != want {
+		t.Fatalf("unexpected totals: %+v", got)
+	}
+	if got.Percent() != 62 {
+		t.Fatalf("expected 62%%, got %d", got.Percent())
+	}
+	if got.String() != "1/3 layers, downloaded 3.5kB / 4kB, extracted 1.5kB / 4kB (62%)" {
+		t.Fatalf("unexpected summary: %q", got.String())
+	}
+
+	p.Update("aaaaaaaaaaaa", "Pull complete", "", 0, 0)
+	p.Update("cccccccccccc", "Pull complete", "", 0, 0)
+	got = p.Totals()
+	if got.Done != 3 || got.Percent() != 100 {
+		t.Fatalf("expected finished pull, got %+v", got)
+	}
+}
+
+func TestLayerTotalsWithoutSizes(t *testing.T) {
+	p := service.NewLayerProgress()
+	p.Update("aaaaaaaaaaaa", "Preparing", "", 0, 0)
+	p.Update("bbbbbbbbbbbb", "Mounted from library/alpine", "", 0, 0)
+
+	got := p.Totals()
+	if got.Percent() != -1 {
+		t.Fatalf("expected unknown percent, got %d", got.Percent())
+	}
+	if got.String() != "1/2 layers" {
+		t.Fatalf("unexpected summary: %q", got.String())
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+)
+
+// generatedDockerfileName is the context entry that holds a dockerfile_inline or
+// recipe Dockerfile, chosen so it does not replace a Dockerfile in cwd.
+const generatedDockerfileName = ".cradle.Dockerfile"
+
+// installCommands holds the package manager commands a recipe is built from.
+type installCommands struct {
+	prepare string // runs before install, e.g. refreshing the package index
+	install string // followed by the package names
+	cleanup string
+	group   string // group that grants sudo
+}
+
+var recipeCommands = map[config.PackageManager]installCommands{
+	config.PackageManagerApt: {
+		prepare: "apt-get update -y",
+		install: "DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends",
+		cleanup: "rm -rf /var/lib/apt/lists/*",
+		group:   "sudo",
+	},
+	config.PackageManagerDnf: {
+		prepare: "dnf -y update",
+		install: "dnf -y install",
+		cleanup: "dnf clean all",
+		group:   "wheel",
+	},
+	config.PackageManagerPacman: {
+		prepare: "pacman -Syu --noconfirm",
+		install: "pacman -S --noconfirm --needed",
+		cleanup: "pacman -Scc --noconfirm",
+		group:   "wheel",
+	},
+	config.PackageManagerZypper: {
+		prepare: "zypper -n --gpg-auto-import-keys refresh",
+		install: "zypper -n install -y",
+		cleanup: "zypper -n clean -a",
+		group:   "wheel",
+	},
+}
+
+// GeneratedDockerfile returns the Dockerfile that dockerfile_inline or recipe
+// produce for b, or "" when b uses a Dockerfile from its context.
+func GeneratedDockerfile(b *config.BuildSpec) (string, error) {
+	if b == nil {
+		return "", nil
+	}
+	if b.DockerfileInline != "" {
+		return b.DockerfileInline, nil
+	}
+	if b.Recipe != nil {
+		return GenerateRecipe(b.Recipe)
+	}
+	return "", nil
+}
+
+// GenerateRecipe renders a recipe as a Dockerfile.
+func GenerateRecipe(recipe *config.RecipeSpec) (string, error) {
+	cmds, ok := recipeCommands[recipe.PackageManager]
+	if !ok {
+		return "", fmt.Errorf("recipe: unsupported package manager %q", recipe.PackageManager)
+	}
+	packages, err := recipePackages(recipe)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\n", recipe.Base)
+	if len(recipe.Setup) > 0 {
+		b.WriteString("\n")
+		writeRun(&b, recipe.Setup)
+	}
+	if len(packages) > 0 {
+		b.WriteString("\n")
+		writeRun(&b, []string{cmds.prepare, cmds.install + " " + strings.Join(packages, " "), cmds.cleanup})
+	}
+	if user := recipe.User; user != nil {
+		writeRecipeUser(&b, user, cmds)
+	}
+	return b.String(), nil
+}
+
+// recipePackages merges the inline packages with packages_file, dropping duplicates.
+// A recipe user with sudo also needs sudo and ca-certificates.
+func recipePackages(recipe *config.RecipeSpec) ([]string, error) {
+	packages := slices.Clone(recipe.Packages)
+	if recipe.PackagesFile != "" {
+		data, err := os.ReadFile(recipe.PackagesFile)
+		if err != nil {
+			return nil, fmt.Errorf("recipe: %w", err)
+		}
+		packages = append(packages, ParsePackageList(data)...)
+	}
+	if recipeSudo(recipe.User) {
+		packages = append(packages, "sudo", "ca-certificates")
+	}
+	out := packages[:0]
+	seen := map[string]bool{}
+	for _, pkg := range packages {
+		if !seen[pkg] {
+			seen[pkg] = true
+			out = append(out, pkg)
+		}
+	}
+	return out, nil
+}
+
+// ParsePackageList reads whitespace-separated package names; # starts a comment.
+func ParsePackageList(data []byte) []string {
+	var out []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		out = append(out, strings.Fields(line)...)
+	}
+	return out
+}
+
+func recipeSudo(user *config.RecipeUserSpec) bool {
+	if user == nil || user.Name == "root" {
+		return false
+	}
+	return user.Sudo == nil || *user.Sudo
+}
+
+func writeRecipeUser(b *strings.Builder, user *config.RecipeUserSpec, cmds installCommands) {
+	if user.Name == "root" {
+		b.WriteString("\nUSER root\nENV USERNAME=root\nWORKDIR /root\n")
+		return
+	}
+	uid := config.DefaultRecipeUID
+	if user.UID != nil {
+		uid = *user.UID
+	}
+	fmt.Fprintf(b, "\nARG USERNAME=%s\nARG UID=%s\n\n", user.Name, strconv.Itoa(uid))
+
+	steps := []string{
+		"set -eux",
+		`if getent passwd "${UID}" >/dev/null; then ` +
+			`olduser="$(getent passwd "${UID}" | cut -d: -f1)"; ` +
+			`userdel -r "${olduser}" || userdel "${olduser}" || true; fi`,
+		`if id -u "${USERNAME}" >/dev/null 2>&1; then ` +
+			`userdel -r "${USERNAME}" || userdel "${USERNAME}" || true; fi`,
+		fmt.Sprintf(`useradd -m -s %s -u "${UID}" "${USERNAME}"`, user.Shell),
+	}
+	if recipeSudo(user) {
+		steps = append(steps,
+			fmt.Sprintf("getent group %[1]s >/dev/null 2>&1 || groupadd -r %[1]s", cmds.group),
+			fmt.Sprintf(`usermod -aG %s "${USERNAME}"`, cmds.group),
+			"mkdir -p /etc/sudoers.d",
+			`echo "${USERNAME} ALL=(ALL) NOPASSWD:ALL" > "/etc/sudoers.d/${USERNAME}"`,
+			`chmod 0440 "/etc/sudoers.d/${USERNAME}"`,
+		)
+	}
+	writeRunSteps(b, steps)
+	b.WriteString("\nUSER ${USERNAME}\nENV USERNAME=${USERNAME}\nWORKDIR /home/${USERNAME}\n")
+}
+
+// writeRun writes commands as one RUN instruction joined with &&.
+func writeRun(b *strings.Builder, commands []string) {
+	b.WriteString("RUN " + strings.Join(commands, " && \\\n  ") + "\n")
+}
+
+// writeRunSteps writes a set -e style RUN instruction with one step per line.
+func writeRunSteps(b *strings.Builder, steps []string) {
+	b.WriteString("RUN " + strings.Join(steps, "; \\\n  ") + "\n")
+}
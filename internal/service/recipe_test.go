@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestParsePackageList(t *testing.T) {
+	got := service.ParsePackageList([]byte("gcc gcc-c++\n# tools\n\nmake # build\n"))
+	want := []string{"gcc", "gcc-c++", "make"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParsePackageList = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateRecipe(t *testing.T) {
+	dir := t.TempDir()
+	packagesFile := filepath.Join(dir, "packages.txt")
+	if err := os.WriteFile(packagesFile, []byte("make\ngit\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uid := 1001
+	dockerfile, err := service.GenerateRecipe(&config.RecipeSpec{
+		Base:           "fedora:44",
+		PackageManager: config.PackageManagerDnf,
+		Packages:       []string{"git", "gcc"},
+		PackagesFile:   packagesFile,
+		Setup:          []string{"echo setup"},
+		User:           &config.RecipeUserSpec{Name: "dev", UID: &uid, Shell: "/bin/zsh"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateRecipe error: %v", err)
+	}
+	for _, want := range []string{
+		"FROM fedora:44\n",
+		"RUN echo setup\n",
+		"dnf -y install git gcc make sudo ca-certificates",
+		"ARG USERNAME=dev\nARG UID=1001\n",
+		`useradd -m -s /bin/zsh -u "${UID}" "${USERNAME}"`,
+		`usermod -aG wheel "${USERNAME}"`,
+		"USER ${USERNAME}\n",
+	} {
+		if !strings.Contains(dockerfile, want) {
+			t.Fatalf("expected %q in Dockerfile:\n%s", want, dockerfile)
+		}
+	}
+	if strings.Index(dockerfile, "echo setup") > strings.Index(dockerfile, "dnf -y install") {
+		t.Fatalf("expected setup before package install:\n%s", dockerfile)
+	}
+}
+
+func TestGenerateRecipeNoSudo(t *testing.T) {
+	sudo := false
+	dockerfile, err := service.GenerateRecipe(&config.RecipeSpec{
+		Base:           "ubuntu:24.04",
+		PackageManager: config.PackageManagerApt,
+		User:           &config.RecipeUserSpec{Name: "dev", Sudo: &sudo, Shell: "/bin/bash"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateRecipe error: %v", err)
+	}
+	if strings.Contains(dockerfile, "sudo") || strings.Contains(dockerfile, "apt-get") {
+		t.Fatalf("expected no package install or sudo setup:\n%s", dockerfile)
+	}
+}
+
+func TestGeneratedDockerfile(t *testing.T) {
+	got, err := service.GeneratedDockerfile(&config.BuildSpec{DockerfileInline: "FROM alpine\n"})
+	if err != nil || got != "FROM alpine\n" {
+		t.Fatalf("unexpected inline Dockerfile %q, %v", got, err)
+	}
+	got, err = service.GeneratedDockerfile(&config.BuildSpec{Cwd: "/tmp"})
+	if err != nil || got != "" {
+		t.Fatalf("expected no generated Dockerfile, got %q, %v", got, err)
+	}
+
+	opts, err := service.BuildOptionsFromSpec(&config.BuildSpec{DockerfileInline: "FROM alpine\n"}, "cradle/x:latest")
+	if err != nil {
+		t.Fatalf("BuildOptionsFromSpec error: %v", err)
+	}
+	if opts.Dockerfile != ".cradle.Dockerfile" {
+		t.Fatalf("unexpected Dockerfile name %q", opts.Dockerfile)
+	}
+}
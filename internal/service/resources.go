@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/moby/moby/client"
+)
+
+// managedLabel marks networks and volumes created by cradle, so they can be found
+// with a label filter.
+const managedLabel = "io.cradle.managed"
+
+const bindSourcePerm = 0o755
+
+// ReferencedNetworks returns the declared networks run attaches to, sorted by name.
+func ReferencedNetworks(cfg *config.Config, run config.RunSpec) []string {
+	seen := map[string]bool{}
+	if _, ok := cfg.Networks[run.NetworkMode]; ok {
+		seen[run.NetworkMode] = true
+	}
+	for name := range run.Networks {
+		if _, ok := cfg.Networks[name]; ok {
+			seen[name] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// ReferencedVolumes returns the declared named volumes run mounts, sorted by name.
+func ReferencedVolumes(cfg *config.Config, run config.RunSpec) []string {
+	seen := map[string]bool{}
+	for _, mount := range run.Volumes {
+		if mount.Type != "volume" {
+			continue
+		}
+		if _, ok := cfg.Volumes[mount.Source]; ok {
+			seen[mount.Source] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// ManagedLabels returns labels plus the cradle ownership label.
+func ManagedLabels(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	maps.Copy(out, labels)
+	out[managedLabel] = "true"
+	return out
+}
+
+// EnsureBindSources creates missing bind mount sources marked create: true.
+func EnsureBindSources(mounts []config.MountSpec) error {
+	for _, m := range mounts {
+		if m.Type != "bind" || m.Create == nil || !*m.Create {
+			continue
+		}
+		if err := os.MkdirAll(m.Source, bindSourcePerm); err != nil {
+			return fmt.Errorf("create bind source %s: %w", m.Source, err)
+		}
+	}
+	return nil
+}
+
+// ensureResources creates the declared networks and volumes run needs, plus bind
+// sources marked create: true. External resources are only checked for existence.
+func (s *Service) ensureResources(ctx context.Context, run config.RunSpec) error {
+	if err := EnsureBindSources(run.Volumes); err != nil {
+		return err
+	}
+	for _, name := range ReferencedNetworks(s.cfg, run) {
+		if err := s.ensureNetwork(ctx, name, s.cfg.Networks[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range ReferencedVolumes(s.cfg, run) {
+		if err := s.ensureVolume(ctx, name, s.cfg.Volumes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) ensureNetwork(ctx context.Context, name string, spec config.DeclaredNetworkSpec) error {
+	_, err := s.cli.NetworkInspect(ctx, name, client.NetworkInspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+	if spec.External {
+		return fmt.Errorf("external network %q does not exist", name)
+	}
+	_, err = s.cli.NetworkCreate(ctx, name, client.NetworkCreateOptions{
+		Driver:   spec.Driver,
+		Internal: spec.Internal,
+		Options:  spec.Options,
+		Labels:   ManagedLabels(spec.Labels),
+	})
+	if err != nil {
+		return fmt.Errorf("create network %q: %w", name, err)
+	}
+	return nil
+}
+
+func (s *Service) ensureVolume(ctx context.Context, name string, spec config.DeclaredVolumeSpec) error {
+	_, err := s.cli.VolumeInspect(ctx, name, client.VolumeInspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+	if spec.External {
+		return fmt.Errorf("external volume %q does not exist", name)
+	}
+	_, err = s.cli.VolumeCreate(ctx, client.VolumeCreateOptions{
+		Name:       name,
+		Driver:     spec.Driver,
+		DriverOpts: spec.Options,
+		Labels:     ManagedLabels(spec.Labels),
+	})
+	if err != nil {
+		return fmt.Errorf("create volume %q: %w", name, err)
+	}
+	return nil
+}
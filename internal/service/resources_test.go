@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestReferencedResources(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.DeclaredNetworkSpec{"devnet": {}, "shared": {External: true}, "unused": {}},
+		Volumes:  map[string]config.DeclaredVolumeSpec{"cache": {}, "unused": {}},
+	}
+	run := config.RunSpec{
+		NetworkMode: "devnet",
+		Networks:    map[string]config.NetworkSpec{"shared": {}, "default": {}},
+		Volumes: []config.MountSpec{
+			{Type: "volume", Source: "cache", Target: "/cache"},
+			{Type: "bind", Source: "/tmp", Target: "/tmp"},
+		},
+	}
+	if got := service.ReferencedNetworks(cfg, run); !reflect.DeepEqual(got, []string{"devnet", "shared"}) {
+		t.Fatalf("unexpected networks: %v", got)
+	}
+	if got := service.ReferencedVolumes(cfg, run); !reflect.DeepEqual(got, []string{"cache"}) {
+		t.Fatalf("unexpected volumes: %v", got)
+	}
+}
+
+func TestManagedLabels(t *testing.T) {
+	labels := map[string]string{"team": "dev"}
+	got := service.ManagedLabels(labels)
+	if got["team"] != "dev" || got["io.cradle.managed"] != "true" {
+		t.Fatalf("unexpected labels: %v", got)
+	}
+	if _, ok := labels["io.cradle.managed"]; ok {
+		t.Fatalf("expected input labels to be left untouched")
+	}
+}
+
+func TestEnsureBindSources(t *testing.T) {
+	dir := t.TempDir()
+	create := true
+	skip := false
+	created := filepath.Join(dir, "cache", "npm")
+	skipped := filepath.Join(dir, "skipped")
+	err := service.EnsureBindSources([]config.MountSpec{
+		{Type: "bind", Source: created, Target: "/cache", Create: &create},
+		{Type: "bind", Source: skipped, Target: "/skipped", Create: &skip},
+		{Type: "tmpfs", Target: "/tmp"},
+	})
+	if err != nil {
+		t.Fatalf("EnsureBindSources error: %v", err)
+	}
+	if info, statErr := os.Stat(created); statErr != nil || !info.IsDir() {
+		t.Fatalf("expected %s to be created: %v", created, statErr)
+	}
+	if _, statErr := os.Stat(skipped); !os.IsNotExist(statErr) {
+		t.Fatalf("expected %s to be left alone, got %v", skipped, statErr)
+	}
+}
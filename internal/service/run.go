@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -14,6 +15,7 @@ import (
 	"os/signal"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -31,10 +33,26 @@ import (
 )
 
 type RunResult struct {
-	ID         string
-	AutoRemove bool
-	Attach     bool
-	TTY        bool
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	AutoRemove bool   `json:"auto_remove"`
+	Attach     bool   `json:"attach"`
+	TTY        bool   `json:"tty"`
+	// Ports lists the host ports published after start.
+	Ports []PublishedPort `json:"ports"`
+
+	// started reports that this run created or started the container, rather than
+	// reusing one that was already running.
+	started bool
+}
+
+// RunOptions holds per-invocation settings that are not part of the alias config.
+type RunOptions struct {
+	// Here mounts the invoking project directory as if run.mount_cwd were enabled.
+	Here bool
+	// Ephemeral runs a uniquely named, auto-removed container and leaves the
+	// alias container untouched.
+	Ephemeral bool
 }
 
 type AttachOptions struct {
@@ -47,6 +65,8 @@ type AttachOptions struct {
 
 const containerFingerprintLabel = "io.cradle.fingerprint"
 const (
+	ephemeralSuffixBytes = 4
+
 	nanoCPUsPerCPU    = 1_000_000_000
 	singlePortPart    = 1
 	hostPortParts     = 2
@@ -69,25 +89,38 @@ type runFlags struct {
 
 func (s *Service) Run(
 	ctx context.Context,
-	alias string,
+	target Target,
 	out io.Writer,
 	overrides ImagePolicyOverrides,
+	opts RunOptions,
 ) (*RunResult, error) {
+	alias := target.Alias
 	a, found := s.cfg.Aliases[alias]
 	if !found {
 		return nil, fmt.Errorf("unknown alias %q", alias)
 	}
+	createName, run, err := s.containerTarget(target, opts.Here)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Ephemeral {
+		createName = EphemeralContainerName(createName)
+	}
 
-	imageRef, err := s.EnsureImage(ctx, alias, out, overrides)
+	imageRef, err := s.runImage(ctx, alias, out, overrides)
 	if err != nil {
 		return nil, err
 	}
 
-	createName := defaultContainerName(alias, a.Run.Name)
+	hookEnv := HookEnv{Alias: alias, Instance: target.Instance, ImageRef: imageRef, ContainerName: createName}
+	if hookErr := s.runHooks(ctx, HookPreRun, a.PreRun, hookEnv, out); hookErr != nil {
+		return nil, hookErr
+	}
+
 	flags := runFlags{
 		tty:        BoolDefault(a.Run.TTY, false),
 		stdinOpen:  BoolDefault(a.Run.StdinOpen, false),
-		autoRemove: BoolDefault(a.Run.AutoRemove, false),
+		autoRemove: opts.Ephemeral || BoolDefault(a.Run.AutoRemove, false),
 		attach:     BoolDefault(a.Run.Attach, false),
 	}
 
@@ -96,12 +129,23 @@ func (s *Service) Run(
 		return nil, err
 	}
 
+	run, err = effectiveRunSpec(run, createName, imageInfo)
+	if err != nil {
+		return nil, err
+	}
+	if forwardErr := PrepareForwards(ctx, run.Forward); forwardErr != nil {
+		return nil, forwardErr
+	}
+	if resErr := s.ensureResources(ctx, run); resErr != nil {
+		return nil, resErr
+	}
+
 	fingerprint, err := RunFingerprint(
 		alias,
 		createName,
 		imageRef,
 		imageInfo.ID,
-		a.Run,
+		run,
 		flags.tty,
 		flags.stdinOpen,
 		flags.autoRemove,
@@ -110,32 +154,115 @@ func (s *Service) Run(
 		return nil, err
 	}
 
-	if result, reused, reuseErr := s.tryReuseContainer(
-		ctx,
-		createName,
-		fingerprint,
-		flags.autoRemove,
-		flags.attach,
-		flags.tty,
-	); reuseErr != nil {
-		return nil, reuseErr
-	} else if reused {
-		return result, nil
+	result, err := s.startContainer(ctx, createName, run, imageRef, fingerprint, flags, !opts.Ephemeral)
+	if err != nil {
+		return nil, err
 	}
+	result.Name = createName
+	result.Ports = ApplyPortURLs(s.publishedPorts(ctx, result.ID), run.URLs)
+
+	hookEnv.ContainerID = result.ID
+	if hookErr := s.runHooks(ctx, HookPostRun, a.PostRun, hookEnv, out); hookErr != nil {
+		switch {
+		case !result.started:
+		case opts.Ephemeral:
+			s.removeContainer(ctx, result.ID)
+		default:
+			s.stopContainer(ctx, result.ID)
+		}
+		return nil, hookErr
+	}
+	// A signal before attach cancels ctx; nothing will attach, so remove the --rm container.
+	if opts.Ephemeral && ctx.Err() != nil {
+		s.removeContainer(ctx, result.ID)
+		return nil, ctx.Err()
+	}
+	return result, nil
+}
 
-	id, createErr := s.createContainer(ctx, createName, a.Run, imageRef, fingerprint, flags)
-	if createErr != nil {
-		return nil, createErr
+// startContainer reuses the container called name when reuse is set and its fingerprint
+// matches, otherwise it creates and starts a new one.
+func (s *Service) startContainer(
+	ctx context.Context,
+	name string,
+	run config.RunSpec,
+	imageRef string,
+	fingerprint string,
+	flags runFlags,
+	reuse bool,
+) (*RunResult, error) {
+	if reuse {
+		result, reused, err := s.tryReuseContainer(ctx, name, fingerprint, flags.autoRemove, flags.attach, flags.tty)
+		if err != nil {
+			return nil, err
+		}
+		if reused {
+			return result, nil
+		}
 	}
 
+	id, err := s.createContainer(ctx, name, run, imageRef, fingerprint, flags)
+	if err != nil {
+		return nil, err
+	}
 	return &RunResult{
 		ID:         id,
 		AutoRemove: flags.autoRemove,
 		Attach:     flags.attach,
 		TTY:        flags.tty,
+		started:    true,
 	}, nil
 }
 
+// EphemeralContainerName derives a unique container name from base for --rm runs.
+func EphemeralContainerName(base string) string {
+	suffix := make([]byte, ephemeralSuffixBytes)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-rm-%s", base, hex.EncodeToString(suffix))
+}
+
+// removeContainer force-removes a container. It runs even when ctx was cancelled.
+func (s *Service) removeContainer(ctx context.Context, id string) {
+	_, _ = s.cli.ContainerRemove(context.WithoutCancel(ctx), id, client.ContainerRemoveOptions{Force: true})
+}
+
+// stopContainer stops a container that was started but must not keep running.
+func (s *Service) stopContainer(ctx context.Context, id string) {
+	_, _ = s.cli.ContainerStop(context.WithoutCancel(ctx), id, client.ContainerStopOptions{})
+}
+
+func effectiveRunSpec(
+	run config.RunSpec,
+	containerName string,
+	imageInfo client.ImageInspectResult,
+) (config.RunSpec, error) {
+	if run.HostUser {
+		identity, err := CurrentHostIdentity()
+		if err != nil {
+			return run, fmt.Errorf("resolve host user: %w", err)
+		}
+		var imageEntrypoint, imageCmd []string
+		if imageInfo.Config != nil {
+			imageEntrypoint = imageInfo.Config.Entrypoint
+			imageCmd = imageInfo.Config.Cmd
+		}
+		run = ApplyHostUser(run, identity, imageEntrypoint, imageCmd)
+	}
+
+	if run.Display != "" {
+		session, err := ResolveDisplay(run.Display)
+		if err != nil {
+			return run, fmt.Errorf("run.display: %w", err)
+		}
+		session, err = session.WithXauthority(DisplayXauthPath(containerName))
+		if err != nil {
+			return run, fmt.Errorf("run.display: %w", err)
+		}
+		run = ApplyDisplay(run, session)
+	}
+	return run, nil
+}
+
 func (s *Service) createContainer(
 	ctx context.Context,
 	name string,
@@ -157,12 +284,25 @@ func (s *Service) createContainer(
 		return "", err
 	}
 
+	if !createOpts.HostConfig.NetworkMode.IsHost() && s.localDaemon() {
+		if portErr := CheckHostPorts(createOpts.HostConfig.PortBindings); portErr != nil {
+			return "", portErr
+		}
+	}
+
 	created, err := s.cli.ContainerCreate(ctx, createOpts)
 	if err != nil {
+		// A cancelled create may still have made the container; remove it by name.
+		if flags.autoRemove && ctx.Err() != nil {
+			s.removeContainer(ctx, name)
+		}
 		return "", err
 	}
 
 	if _, startErr := s.cli.ContainerStart(ctx, created.ID, client.ContainerStartOptions{}); startErr != nil {
+		if flags.autoRemove {
+			s.removeContainer(ctx, created.ID)
+		}
 		return "", startErr
 	}
 
@@ -191,6 +331,13 @@ func BuildContainerCreateOptions(
 		return client.ContainerCreateOptions{}, err
 	}
 
+	forwards, err := ResolveForwards(run.Forward)
+	if err != nil {
+		return client.ContainerCreateOptions{}, err
+	}
+	hostCfg.Mounts = append(hostCfg.Mounts, forwards.Mounts...)
+	env = append(env, forwards.Env...)
+
 	exposed, bindings, err := ParsePorts(run.Ports)
 	if err != nil {
 		return client.ContainerCreateOptions{}, err
@@ -252,7 +399,13 @@ func BuildContainerCreateOptions(
 	return createOpts, nil
 }
 
+// AttachAndWait streams the container until it exits or ctx is cancelled. AutoRemove
+// containers are removed on every return path, including cancellation by a signal.
 func (s *Service) AttachAndWait(ctx context.Context, opts AttachOptions) error {
+	if opts.AutoRemove {
+		defer s.removeContainer(ctx, opts.ID)
+	}
+
 	attached, err := s.cli.ContainerAttach(ctx, opts.ID, client.ContainerAttachOptions{
 		Stream: true, Stdin: true, Stdout: true, Stderr: true,
 	})
@@ -261,45 +414,25 @@ func (s *Service) AttachAndWait(ctx context.Context, opts AttachOptions) error {
 	}
 	defer attached.Close()
 
-	stdinFD := 0
-	hasStdinFD := opts.Stdin != nil
-	if hasStdinFD {
-		stdinFD, hasStdinFD = termutil.Int(opts.Stdin.Fd())
-	}
-
-	if opts.TTY && hasStdinFD && term.IsTerminal(stdinFD) {
-		oldState, setRawErr := term.MakeRaw(stdinFD)
-		if setRawErr == nil {
-			defer func() {
-				_ = term.Restore(stdinFD, oldState)
-			}()
-		}
-	}
-
-	if opts.TTY && hasStdinFD {
-		resize := func() {
-			w, h, sizeErr := term.GetSize(stdinFD)
-			if sizeErr != nil || w < 0 || h < 0 {
-				return
-			}
-			_, _ = s.cli.ContainerResize(context.Background(), opts.ID, client.ContainerResizeOptions{
-				Width:  uint(w),
-				Height: uint(h),
-			})
-		}
-		resize()
-		winch := make(chan os.Signal, 1)
-		signal.Notify(winch, syscall.SIGWINCH)
-		defer signal.Stop(winch)
-		go func() {
-			for range winch {
-				resize()
-			}
-		}()
-	}
+	restore := attachTerminal(opts.Stdin, opts.TTY, func(width, height uint) {
+		_, _ = s.cli.ContainerResize(context.Background(), opts.ID, client.ContainerResizeOptions{
+			Width:  width,
+			Height: height,
+		})
+	})
+	defer restore()
 
 	go func() { _, _ = io.Copy(attached.Conn, opts.Stdin) }()
-	_, _ = io.Copy(opts.Stdout, attached.Reader)
+	streamDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(opts.Stdout, attached.Reader)
+		close(streamDone)
+	}()
+	select {
+	case <-streamDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	wait := s.cli.ContainerWait(ctx, opts.ID, client.ContainerWaitOptions{Condition: container.WaitConditionNotRunning})
 	select {
@@ -309,12 +442,50 @@ func (s *Service) AttachAndWait(ctx context.Context, opts AttachOptions) error {
 		}
 	case <-wait.Result:
 	}
+	return nil
+}
 
-	if !opts.AutoRemove {
-		return nil
+// attachTerminal puts stdin into raw mode when tty is set and it is a terminal, and
+// keeps the remote terminal size in sync through resize. The returned func restores
+// the terminal.
+func attachTerminal(stdin *os.File, tty bool, resize func(width, height uint)) func() {
+	if !tty || stdin == nil {
+		return func() {}
+	}
+	stdinFD, ok := termutil.Int(stdin.Fd())
+	if !ok {
+		return func() {}
+	}
+
+	var oldState *term.State
+	if term.IsTerminal(stdinFD) {
+		if state, err := term.MakeRaw(stdinFD); err == nil {
+			oldState = state
+		}
+	}
+
+	sync := func() {
+		w, h, sizeErr := term.GetSize(stdinFD)
+		if sizeErr != nil || w < 0 || h < 0 {
+			return
+		}
+		resize(uint(w), uint(h))
+	}
+	sync()
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			sync()
+		}
+	}()
+
+	return func() {
+		signal.Stop(winch)
+		if oldState != nil {
+			_ = term.Restore(stdinFD, oldState)
+		}
 	}
-	_, _ = s.cli.ContainerRemove(context.Background(), opts.ID, client.ContainerRemoveOptions{Force: true})
-	return nil
 }
 
 func (s *Service) tryReuseContainer(
@@ -325,6 +496,7 @@ func (s *Service) tryReuseContainer(
 	ctr, err := s.cli.ContainerInspect(ctx, name, client.ContainerInspectOptions{})
 	if err != nil {
 		if errdefs.IsNotFound(err) {
+			s.log.Debug("no container to reuse", "container", name)
 			return nil, false, nil
 		}
 		return nil, false, err
@@ -336,6 +508,8 @@ func (s *Service) tryReuseContainer(
 	}
 
 	if labels[containerFingerprintLabel] != fingerprint {
+		s.log.Debug("recreating container with changed fingerprint", "container", name,
+			"old", labels[containerFingerprintLabel], "new", fingerprint)
 		if ctr.Container.State != nil && ctr.Container.State.Running {
 			_, _ = s.cli.ContainerStop(ctx, ctr.Container.ID, client.ContainerStopOptions{})
 		}
@@ -343,7 +517,9 @@ func (s *Service) tryReuseContainer(
 		return nil, false, nil
 	}
 
-	if ctr.Container.State == nil || !ctr.Container.State.Running {
+	s.log.Debug("reusing container", "container", name, "fingerprint", fingerprint)
+	started := ctr.Container.State == nil || !ctr.Container.State.Running
+	if started {
 		if _, startErr := s.cli.ContainerStart(ctx, ctr.Container.ID, client.ContainerStartOptions{}); startErr != nil {
 			return nil, false, startErr
 		}
@@ -354,6 +530,7 @@ func (s *Service) tryReuseContainer(
 		AutoRemove: autoRemove,
 		Attach:     attach,
 		TTY:        tty,
+		started:    started,
 	}, true, nil
 }
 
@@ -401,6 +578,8 @@ type runFingerprintRun struct {
 	UTS             string                  `json:"uts"`
 	Runtime         string                  `json:"runtime"`
 	Volumes         []config.MountSpec      `json:"volumes"`
+	Forward         []config.ForwardKind    `json:"forward"`
+	Display         config.DisplayMode      `json:"display"`
 	Resources       *config.ResourcesSpec   `json:"resources,omitempty"`
 	Privileged      bool                    `json:"privileged"`
 	ReadOnly        bool                    `json:"read_only"`
@@ -470,6 +649,8 @@ func buildRunFingerprintRun(run config.RunSpec, tty, stdinOpen, autoRemove bool)
 		UTS:             run.UTS,
 		Runtime:         run.Runtime,
 		Volumes:         run.Volumes,
+		Forward:         run.Forward,
+		Display:         run.Display,
 		Resources:       run.Resources,
 		Privileged:      run.Privileged,
 		ReadOnly:        run.ReadOnly,
@@ -863,7 +1044,6 @@ func buildHostConfig(
 		Privileged:     run.Privileged,
 		NetworkMode:    container.NetworkMode(run.NetworkMode),
 		ExtraHosts:     run.ExtraHosts,
-		Mounts:         ToDockerMounts(run.Volumes),
 		Resources:      resources,
 		ReadonlyRootfs: run.ReadOnly,
 		CapAdd:         run.CapAdd,
@@ -874,6 +1054,12 @@ func buildHostConfig(
 		Runtime:        run.Runtime,
 	}
 
+	mounts, err := ToDockerMounts(run.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	hostCfg.Mounts = mounts
+
 	if len(run.DNS) > 0 {
 		dns, err := parseDNS(run.DNS)
 		if err != nil {
@@ -928,32 +1114,67 @@ func buildHostConfig(
 	return hostCfg, nil
 }
 
-func ToDockerMounts(ms []config.MountSpec) []mount.Mount {
+func ToDockerMounts(ms []config.MountSpec) ([]mount.Mount, error) {
 	out := make([]mount.Mount, 0, len(ms))
 	for _, m := range ms {
+		dm := mount.Mount{Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly}
 		switch m.Type {
 		case "bind":
-			out = append(out, mount.Mount{
-				Type:     mount.TypeBind,
-				Source:   m.Source,
-				Target:   m.Target,
-				ReadOnly: m.ReadOnly,
-			})
+			dm.Type = mount.TypeBind
+			if m.Bind != nil {
+				dm.BindOptions = &mount.BindOptions{
+					Propagation:      mount.Propagation(m.Bind.Propagation),
+					CreateMountpoint: m.Bind.CreateHostPath,
+				}
+			}
 		case "volume":
-			out = append(out, mount.Mount{
-				Type:     mount.TypeVolume,
-				Source:   m.Source,
-				Target:   m.Target,
-				ReadOnly: m.ReadOnly,
-			})
+			dm.Type = mount.TypeVolume
+			if m.Volume != nil {
+				dm.VolumeOptions = buildVolumeOptions(*m.Volume)
+			}
 		case "tmpfs":
-			out = append(out, mount.Mount{
-				Type:   mount.TypeTmpfs,
-				Target: m.Target,
-			})
+			dm.Type = mount.TypeTmpfs
+			dm.Source = ""
+			if m.Tmpfs != nil {
+				opts, err := buildTmpfsOptions(*m.Tmpfs)
+				if err != nil {
+					return nil, fmt.Errorf("tmpfs mount %s: %w", m.Target, err)
+				}
+				dm.TmpfsOptions = opts
+			}
+		default:
+			continue
 		}
+		out = append(out, dm)
 	}
-	return out
+	return out, nil
+}
+
+func buildVolumeOptions(spec config.VolumeOptionsSpec) *mount.VolumeOptions {
+	opts := &mount.VolumeOptions{NoCopy: spec.NoCopy, Subpath: spec.Subpath}
+	if spec.Driver != "" || len(spec.Options) > 0 {
+		opts.DriverConfig = &mount.Driver{Name: spec.Driver, Options: spec.Options}
+	}
+	return opts
+}
+
+func buildTmpfsOptions(spec config.TmpfsOptionsSpec) (*mount.TmpfsOptions, error) {
+	opts := &mount.TmpfsOptions{}
+	if spec.Size != "" {
+		size, err := units.RAMInBytes(spec.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size: %w", err)
+		}
+		opts.SizeBytes = size
+	}
+	if spec.Mode != "" {
+		mode, err := strconv.ParseUint(spec.Mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mode: %w", err)
+		}
+		opts.Mode = os.FileMode(mode)
+	}
+	return opts, nil
 }
 
 func ParsePorts(specs []string) (mobynet.PortSet, mobynet.PortMap, error) {
@@ -1046,9 +1267,12 @@ func splitIPv4Port(spec string) (string, string, string, error) {
 }
 
 func buildPortBinding(hostIPStr, hostPortStr, spec string) (mobynet.PortBinding, bool, error) {
-	if hostPortStr == "" {
+	if hostPortStr == "" && hostIPStr == "" {
 		return mobynet.PortBinding{}, false, nil
 	}
+	if hostPortStr == autoHostPort || hostPortStr == "0" {
+		hostPortStr = ""
+	}
 
 	binding := mobynet.PortBinding{HostPort: hostPortStr}
 	if hostIPStr == "" {
@@ -1,11 +1,15 @@
 package service_test
 
 import (
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/config"
 	"github.com/rhajizada/cradle/internal/service"
+
+	"github.com/moby/moby/api/types/mount"
 )
 
 func TestNormalizeTrimmedSlice(t *testing.T) {
@@ -39,7 +43,10 @@ func TestToDockerMounts(t *testing.T) {
 		{Type: "volume", Source: "data", Target: "/data"},
 		{Type: "tmpfs", Target: "/tmp"},
 	}
-	out := service.ToDockerMounts(mounts)
+	out, err := service.ToDockerMounts(mounts)
+	if err != nil {
+		t.Fatalf("ToDockerMounts error: %v", err)
+	}
 	if len(out) != 3 {
 		t.Fatalf("expected 3 mounts, got %d", len(out))
 	}
@@ -50,3 +57,74 @@ func TestToDockerMounts(t *testing.T) {
 		t.Fatalf("expected mount types to be set")
 	}
 }
+
+func TestToDockerMountsOptions(t *testing.T) {
+	mounts := []config.MountSpec{
+		{
+			Type:   "bind",
+			Source: "/src",
+			Target: "/dst",
+			Bind:   &config.BindOptionsSpec{Propagation: "rshared", CreateHostPath: true},
+		},
+		{
+			Type:   "volume",
+			Source: "data",
+			Target: "/data",
+			Volume: &config.VolumeOptionsSpec{
+				NoCopy:  true,
+				Subpath: "app",
+				Driver:  "local",
+				Options: map[string]string{"type": "nfs"},
+			},
+		},
+		{Type: "tmpfs", Target: "/tmp", ReadOnly: true, Tmpfs: &config.TmpfsOptionsSpec{Size: "64m", Mode: "1777"}},
+	}
+	out, err := service.ToDockerMounts(mounts)
+	if err != nil {
+		t.Fatalf("ToDockerMounts error: %v", err)
+	}
+	if bind := out[0].BindOptions; bind == nil || bind.Propagation != mount.PropagationRShared || !bind.CreateMountpoint {
+		t.Fatalf("unexpected bind options: %#v", out[0].BindOptions)
+	}
+	vol := out[1].VolumeOptions
+	if vol == nil || !vol.NoCopy || vol.Subpath != "app" || vol.DriverConfig == nil ||
+		vol.DriverConfig.Name != "local" || vol.DriverConfig.Options["type"] != "nfs" {
+		t.Fatalf("unexpected volume options: %#v", vol)
+	}
+	tmpfs := out[2].TmpfsOptions
+	if !out[2].ReadOnly || tmpfs == nil || tmpfs.SizeBytes != 64*1024*1024 || tmpfs.Mode != os.FileMode(0o1777) {
+		t.Fatalf("unexpected tmpfs mount: %#v", out[2])
+	}
+
+	bad := []config.MountSpec{{Type: "tmpfs", Target: "/tmp", Tmpfs: &config.TmpfsOptionsSpec{Size: "lots"}}}
+	if _, err = service.ToDockerMounts(bad); err == nil {
+		t.Fatalf("expected error for invalid tmpfs size")
+	}
+}
+
+func TestRunFingerprintMountOptions(t *testing.T) {
+	run := config.RunSpec{Volumes: []config.MountSpec{{Type: "tmpfs", Target: "/tmp"}}}
+	before, err := service.RunFingerprint("alias", "name", "img", "id", run, false, false, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	run.Volumes = []config.MountSpec{{Type: "tmpfs", Target: "/tmp", Tmpfs: &config.TmpfsOptionsSpec{Size: "64m"}}}
+	after, err := service.RunFingerprint("alias", "name", "img", "id", run, false, false, false)
+	if err != nil {
+		t.Fatalf("RunFingerprint error: %v", err)
+	}
+	if before == after {
+		t.Fatalf("expected mount options to change the fingerprint")
+	}
+}
+
+func TestEphemeralContainerName(t *testing.T) {
+	a := service.EphemeralContainerName("cradle-dev")
+	b := service.EphemeralContainerName("cradle-dev")
+	if a == b {
+		t.Fatalf("expected unique names, got %q twice", a)
+	}
+	if !strings.HasPrefix(a, "cradle-dev-rm-") {
+		t.Fatalf("unexpected name: %q", a)
+	}
+}
@@ -1,6 +1,11 @@
 package service_test
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/config"
@@ -93,3 +98,125 @@ func TestRunFingerprintDeterministic(t *testing.T) {
 		t.Fatalf("expected fingerprint to change when gpu config changes")
 	}
 }
+
+func TestParsePortsAuto(t *testing.T) {
+	_, bindings, err := service.ParsePorts([]string{"auto:80", "0:443", "127.0.0.1::22"})
+	if err != nil {
+		t.Fatalf("parsePorts error: %v", err)
+	}
+	for _, raw := range []string{"80", "443", "22"} {
+		port, _ := mobynet.ParsePort(raw)
+		if len(bindings[port]) != 1 || bindings[port][0].HostPort != "" {
+			t.Fatalf("expected auto host port for %s, got %+v", raw, bindings[port])
+		}
+	}
+	p22, _ := mobynet.ParsePort("22")
+	if bindings[p22][0].HostIP.String() != "127.0.0.1" {
+		t.Fatalf("expected host ip to be kept, got %v", bindings[p22][0].HostIP)
+	}
+}
+
+// fakeContainer serves one alias container that survives between runs and counts
+// how often it is stopped and removed.
+type fakeContainer struct {
+	labels  map[string]string
+	running bool
+	stops   int
+	removes int
+}
+
+func (f *fakeContainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/images/ubuntu:24.04/json"):
+		_, _ = io.WriteString(w, `{"Id":"sha256:img","Config":{}}`)
+	case strings.HasSuffix(r.URL.Path, "/containers/create"):
+		var body struct{ Labels map[string]string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.labels = body.Labels
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"Id":"abc"}`)
+	case strings.HasSuffix(r.URL.Path, "/start"):
+		f.running = true
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/stop"):
+		f.running = false
+		f.stops++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/containers/abc"):
+		f.running = false
+		f.removes++
+		w.WriteHeader(http.StatusNoContent)
+	case strings.Contains(r.URL.Path, "/containers/") && strings.HasSuffix(r.URL.Path, "/json") && f.labels != nil:
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"Id":     "abc",
+			"Config": map[string]any{"Labels": f.labels},
+			"State":  map[string]any{"Running": f.running},
+		})
+	default:
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}
+}
+
+func TestRunPostRunFailureKeepsReusedContainer(t *testing.T) {
+	daemon := &fakeContainer{}
+	svc := newFakeDaemonService(t, daemon, &config.Config{
+		BaseDir: t.TempDir(),
+		Aliases: map[string]config.Alias{"demo": {
+			Image:   config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04", Policy: config.ImagePolicyIfMissing}},
+			PostRun: []string{"false"},
+		}},
+	})
+	run := func() error {
+		_, err := svc.Run(context.Background(), service.Target{Alias: "demo"}, io.Discard,
+			service.ImagePolicyOverrides{}, service.RunOptions{})
+		return err
+	}
+
+	cases := []struct {
+		name    string
+		running bool
+		stopped bool
+	}{
+		{name: "created", stopped: true},
+		{name: "reused stopped", stopped: true},
+		{name: "reused running", running: true},
+	}
+	for _, tc := range cases {
+		daemon.running = tc.running
+		stops := daemon.stops
+		if err := run(); err == nil || !strings.Contains(err.Error(), "post_run") {
+			t.Fatalf("%s: expected post_run failure, got %v", tc.name, err)
+		}
+		if stopped := daemon.stops > stops; stopped != tc.stopped {
+			t.Fatalf("%s: expected stopped %v, got %v", tc.name, tc.stopped, stopped)
+		}
+	}
+}
+
+func TestRunEphemeralRemovedWhenCancelledBeforeAttach(t *testing.T) {
+	daemon := &fakeContainer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The signal arrives once the container is started, while its ports are read.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/containers/abc/json") {
+			cancel()
+		}
+		daemon.ServeHTTP(w, r)
+	})
+	svc := newFakeDaemonService(t, handler, &config.Config{
+		BaseDir: t.TempDir(),
+		Aliases: map[string]config.Alias{"demo": {
+			Image: config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04", Policy: config.ImagePolicyIfMissing}},
+		}},
+	})
+
+	_, err := svc.Run(ctx, service.Target{Alias: "demo"}, io.Discard,
+		service.ImagePolicyOverrides{}, service.RunOptions{Ephemeral: true})
+	if err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+	if daemon.removes == 0 {
+		t.Fatalf("expected the --rm container to be removed")
+	}
+}
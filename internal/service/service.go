@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"sort"
 	"strings"
 
@@ -16,6 +17,7 @@ import (
 type Service struct {
 	cfg *config.Config
 	cli *client.Client
+	log *slog.Logger
 }
 
 func New(cfg *config.Config) (*Service, error) {
@@ -23,11 +25,17 @@ func New(cfg *config.Config) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Service{cfg: cfg, cli: cli}, nil
+	return NewWithClient(cfg, cli), nil
 }
 
 func NewWithClient(cfg *config.Config, cli *client.Client) *Service {
-	return &Service{cfg: cfg, cli: cli}
+	return &Service{cfg: cfg, cli: cli, log: slog.New(slog.DiscardHandler)}
+}
+
+// SetLogger sets the logger for debug details such as policy decisions and
+// container reuse. Services log nothing by default.
+func (s *Service) SetLogger(log *slog.Logger) {
+	s.log = log
 }
 
 func (s *Service) Close() error {
@@ -52,6 +60,8 @@ type AliasInfo struct {
 type ImagePolicyOverrides struct {
 	Build *config.ImagePolicy
 	Pull  *config.ImagePolicy
+	// LogFile replaces the timestamped log under BuildLogDir; output is appended.
+	LogFile string
 }
 
 func (a AliasInfo) Description() string {
@@ -103,52 +113,77 @@ func (s *Service) AliasInfo(name string) (AliasInfo, error) {
 }
 
 func (s *Service) Build(ctx context.Context, alias string, out io.Writer, overrides ImagePolicyOverrides) error {
-	a, ok := s.cfg.Aliases[alias]
-	if !ok {
-		return fmt.Errorf("unknown alias %q", alias)
-	}
-	if a.Image.Pull != nil {
-		ref := NormalizeImageRef(a.Image.Pull.Ref)
-		policy := resolveImagePolicy(a.Image.Pull.Policy, overrides.Pull)
-		return s.ensurePull(ctx, a.Image.Pull, ref, out, policy)
-	}
-	if a.Image.Build != nil {
-		policy := resolveImagePolicy(a.Image.Build.Policy, overrides.Build)
-		return s.ensureBuild(ctx, alias, out, policy)
-	}
-	return fmt.Errorf("alias %q has no image", alias)
+	_, err := s.EnsureImage(ctx, alias, out, overrides)
+	return err
 }
 
+// EnsureImage pulls or builds the image of alias as its policy requires and returns
+// its reference. Pull and build output is also written to a build log.
 func (s *Service) EnsureImage(
 	ctx context.Context,
 	alias string,
 	out io.Writer,
 	overrides ImagePolicyOverrides,
-) (string, error) {
-	a, ok := s.cfg.Aliases[alias]
-	if !ok {
-		return "", fmt.Errorf("unknown alias %q", alias)
+) (_ string, err error) {
+	info, err := s.AliasInfo(alias)
+	if err != nil {
+		return "", err
 	}
+	ref := resolveImageRef(info)
+
+	header := fmt.Sprintf("cradle %s %s (%s)", info.Kind, alias, ref)
+	buildLog := newAliasBuildLog(alias, header)
+	if overrides.LogFile != "" {
+		buildLog = NewBuildLog(overrides.LogFile, header)
+	}
+	buildLog.logger = s.log
+	defer func() {
+		if cerr := buildLog.Close(); cerr != nil {
+			s.log.Warn("build log close failed", "path", buildLog.Path(), "error", cerr)
+		}
+		err = buildLog.annotate(err)
+	}()
+	return s.ensureImage(ctx, alias, ref, &loggedOutput{out: out, log: buildLog}, overrides)
+}
 
+func (s *Service) ensureImage(
+	ctx context.Context,
+	alias, ref string,
+	out io.Writer,
+	overrides ImagePolicyOverrides,
+) (string, error) {
+	a := s.cfg.Aliases[alias]
+	var err error
 	if a.Image.Pull != nil {
-		ref := NormalizeImageRef(a.Image.Pull.Ref)
 		policy := resolveImagePolicy(a.Image.Pull.Policy, overrides.Pull)
-		if err := s.ensurePull(ctx, a.Image.Pull, ref, out, policy); err != nil {
-			return "", err
-		}
-		return ref, nil
+		s.logPolicy(alias, ImagePull, a.Image.Pull.PolicyDefaulted(), overrides.Pull, policy)
+		err = s.ensurePull(ctx, alias, a.Image.Pull, ref, out, policy)
+	} else {
+		policy := resolveImagePolicy(a.Image.Build.Policy, overrides.Build)
+		s.logPolicy(alias, ImageBuild, a.Image.Build.PolicyDefaulted(), overrides.Build, policy)
+		err = s.ensureBuild(ctx, alias, out, policy)
 	}
-
-	if a.Image.Build == nil {
-		return "", fmt.Errorf("alias %q has no image", alias)
+	if err != nil {
+		return "", err
 	}
+	return ref, nil
+}
 
-	tag := imageTag(alias)
-	policy := resolveImagePolicy(a.Image.Build.Policy, overrides.Build)
-	if err := s.ensureBuild(ctx, alias, out, policy); err != nil {
-		return "", err
+func (s *Service) logPolicy(
+	alias string,
+	kind ImageKind,
+	defaulted bool,
+	override *config.ImagePolicy,
+	policy config.ImagePolicy,
+) {
+	source := "config"
+	switch {
+	case override != nil:
+		source = "flag"
+	case defaulted:
+		source = "default"
 	}
-	return tag, nil
+	s.log.Debug("image policy", "alias", alias, "kind", kind, "policy", policy, "source", source)
 }
 
 func resolveImagePolicy(policy config.ImagePolicy, override *config.ImagePolicy) config.ImagePolicy {
@@ -161,14 +196,25 @@ func resolveImagePolicy(policy config.ImagePolicy, override *config.ImagePolicy)
 	return policy
 }
 
+// ensurePull applies the pull policy to ref. When the lock file pins the alias, the
+// pinned digest is pulled and tagged as ref.
 func (s *Service) ensurePull(
 	ctx context.Context,
+	alias string,
 	spec *config.PullSpec,
 	ref string,
 	out io.Writer,
 	policy config.ImagePolicy,
 ) error {
-	exists, err := s.imageExists(ctx, ref)
+	pinned, err := s.pinnedPullRef(alias, ref)
+	if err != nil {
+		return err
+	}
+	pullRef := ref
+	if pinned != "" {
+		pullRef = pinned
+	}
+	exists, err := s.imageExists(ctx, pullRef)
 	if err != nil {
 		return err
 	}
@@ -176,24 +222,38 @@ func (s *Service) ensurePull(
 	if err != nil {
 		return err
 	}
+	pull := func() error {
+		if pullErr := pullImage(ctx, s.cli, pullRef, options, out); pullErr != nil {
+			return pullErr
+		}
+		return s.tagPinned(ctx, pinned, ref)
+	}
 	switch policy {
 	case config.ImagePolicyAlways:
-		return pullImage(ctx, s.cli, ref, options, out)
+		return pull()
 	case config.ImagePolicyIfMissing:
 		if exists {
-			return nil
+			return s.tagPinned(ctx, pinned, ref)
 		}
-		return pullImage(ctx, s.cli, ref, options, out)
+		return pull()
 	case config.ImagePolicyNever:
 		if exists {
-			return nil
+			return s.tagPinned(ctx, pinned, ref)
 		}
-		return fmt.Errorf("image %q not found (pull policy: never)", ref)
+		return fmt.Errorf("image %q not found (pull policy: never)", pullRef)
 	default:
 		return fmt.Errorf("unknown pull policy %q", policy)
 	}
 }
 
+// tagPinned points ref at the pinned image, if there is one.
+func (s *Service) tagPinned(ctx context.Context, pinned, ref string) error {
+	if pinned == "" {
+		return nil
+	}
+	return s.tagImage(ctx, pinned, ref)
+}
+
 func (s *Service) ensureBuild(
 	ctx context.Context,
 	alias string,
@@ -215,14 +275,31 @@ func (s *Service) ensureBuild(
 	if err != nil {
 		return err
 	}
+	hookEnv := HookEnv{Alias: alias, ImageRef: tag}
+	build := func() error {
+		if hookErr := s.runHooks(ctx, HookPreBuild, a.PreBuild, hookEnv, out); hookErr != nil {
+			return hookErr
+		}
+		spec, pinErr := s.pinnedBuildSpec(alias, a.Image.Build)
+		if pinErr != nil {
+			return pinErr
+		}
+		if buildErr := buildImage(ctx, s.cli, s.log, LocalCacheSpec(alias, spec), tag, out); buildErr != nil {
+			return buildErr
+		}
+		if cacheErr := s.exportBuildCache(ctx, a.Image.Build, tag, out); cacheErr != nil {
+			return cacheErr
+		}
+		return s.runHooks(ctx, HookPostBuild, a.PostBuild, hookEnv, out)
+	}
 	switch policy {
 	case config.ImagePolicyAlways:
-		return buildImage(ctx, s.cli, a.Image.Build, tag, out)
+		return build()
 	case config.ImagePolicyIfMissing:
 		if exists {
 			return nil
 		}
-		return buildImage(ctx, s.cli, a.Image.Build, tag, out)
+		return build()
 	case config.ImagePolicyNever:
 		if exists {
 			return nil
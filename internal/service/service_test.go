@@ -2,12 +2,32 @@ package service_test
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/rhajizada/cradle/internal/config"
 	"github.com/rhajizada/cradle/internal/service"
+
+	"github.com/moby/moby/client"
 )
 
+// newFakeDaemonService returns a service for cfg whose Docker client talks to
+// handler. The server and client are closed when the test ends.
+func newFakeDaemonService(t *testing.T, handler http.Handler, cfg *config.Config) *service.Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cli, err := client.New(client.WithHost("tcp://"+strings.TrimPrefix(srv.URL, "http://")), client.WithVersion("1.52"))
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	svc := service.NewWithClient(cfg, cli)
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc
+}
+
 func TestAliasInfoAndListAliases(t *testing.T) {
 	cfg := &config.Config{
 		Aliases: map[string]config.Alias{
@@ -73,3 +93,29 @@ func TestNormalizeImageRef(t *testing.T) {
 		t.Fatalf("unexpected ref: %q", got)
 	}
 }
+
+func TestStartOrderGroup(t *testing.T) {
+	image := config.ImageSpec{Pull: &config.PullSpec{Ref: "ubuntu:24.04"}}
+	cfg := &config.Config{
+		Aliases: map[string]config.Alias{
+			"db":      {Image: image},
+			"cache":   {Image: image},
+			"backend": {Image: image, DependsOn: []string{"db", "cache"}},
+			"worker":  {Image: image, DependsOn: []string{"db"}},
+		},
+		Groups: map[string][]string{"stack": {"backend", "worker"}},
+	}
+	order, err := service.NewWithClient(cfg, nil).StartOrder("stack")
+	if err != nil {
+		t.Fatalf("StartOrder error: %v", err)
+	}
+	want := []string{"db", "cache", "backend", "worker"}
+	if len(order) != len(want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+)
+
+// snapshotLabel marks images created by `cradle commit`.
+const snapshotLabel = "io.cradle.snapshot"
+
+const snapshotTimeLayout = "20060102-150405"
+
+// Snapshot is an image committed from an alias container.
+type Snapshot struct {
+	Alias   string
+	Ref     string
+	ID      string
+	Created time.Time
+}
+
+// SnapshotRef returns the image reference for a snapshot of alias. An empty tag
+// becomes snapshot-<UTC timestamp>.
+func SnapshotRef(alias, tag string, now time.Time) string {
+	if tag == "" {
+		tag = "snapshot-" + now.UTC().Format(snapshotTimeLayout)
+	}
+	return "cradle/" + alias + ":" + tag
+}
+
+// Commit saves the target container's filesystem as a snapshot image of its alias
+// and returns the new image reference.
+func (s *Service) Commit(ctx context.Context, target Target, here bool, tag string) (string, error) {
+	ctr, _, err := s.inspectTarget(ctx, target, here)
+	if err != nil {
+		return "", err
+	}
+	ref := SnapshotRef(target.Alias, tag, time.Now())
+	_, err = s.cli.ContainerCommit(ctx, ctr.Container.ID, client.ContainerCommitOptions{
+		Reference: ref,
+		Comment:   "cradle commit " + target.String(),
+		Changes: []string{
+			fmt.Sprintf("LABEL %s=%q %s=%q", aliasLabel, target.Alias, snapshotLabel, "true"),
+			// Containers created from the snapshot must not look like instances.
+			fmt.Sprintf("LABEL %s=%q", instanceLabel, ""),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit %s: %w", target, err)
+	}
+	return ref, nil
+}
+
+// runImage returns the image to run for alias: its newest snapshot when image.snapshot
+// is set and no pull or build is forced, otherwise the ensured pull/build image.
+func (s *Service) runImage(
+	ctx context.Context,
+	alias string,
+	out io.Writer,
+	overrides ImagePolicyOverrides,
+) (string, error) {
+	a, ok := s.cfg.Aliases[alias]
+	if ok && a.Image.Snapshot && overrides.Pull == nil && overrides.Build == nil {
+		snapshots, err := s.Snapshots(ctx, alias)
+		if err != nil {
+			return "", err
+		}
+		if len(snapshots) > 0 {
+			return snapshots[0].Ref, nil
+		}
+	}
+	return s.EnsureImage(ctx, alias, out, overrides)
+}
+
+// Snapshots returns the snapshot images of alias, newest first.
+func (s *Service) Snapshots(ctx context.Context, alias string) ([]Snapshot, error) {
+	grouped, err := s.snapshotImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return grouped[alias], nil
+}
+
+// snapshotImages lists all snapshot images grouped by alias, newest first.
+func (s *Service) snapshotImages(ctx context.Context) (map[string][]Snapshot, error) {
+	list, err := s.cli.ImageList(ctx, client.ImageListOptions{
+		Filters: make(client.Filters).Add("label", snapshotLabel),
+	})
+	if err != nil {
+		return nil, err
+	}
+	grouped := map[string][]Snapshot{}
+	for _, item := range list.Items {
+		alias := item.Labels[aliasLabel]
+		ref := snapshotTag(item, alias)
+		if alias == "" || ref == "" {
+			continue
+		}
+		grouped[alias] = append(grouped[alias], Snapshot{
+			Alias:   alias,
+			Ref:     ref,
+			ID:      item.ID,
+			Created: time.Unix(item.Created, 0),
+		})
+	}
+	for _, items := range grouped {
+		sort.Slice(items, func(i, j int) bool {
+			if !items[i].Created.Equal(items[j].Created) {
+				return items[i].Created.After(items[j].Created)
+			}
+			return items[i].Ref > items[j].Ref
+		})
+	}
+	return grouped, nil
+}
+
+// snapshotTag picks the image tag in the alias repository, falling back to the first tag.
+func snapshotTag(item image.Summary, alias string) string {
+	for _, tag := range item.RepoTags {
+		if strings.HasPrefix(tag, "cradle/"+alias+":") {
+			return tag
+		}
+	}
+	if len(item.RepoTags) > 0 {
+		return item.RepoTags[0]
+	}
+	return ""
+}
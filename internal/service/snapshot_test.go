@@ -0,0 +1,18 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestSnapshotRef(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 30, 5, 0, time.FixedZone("EST", -5*60*60))
+	if got := service.SnapshotRef("code", "", now); got != "cradle/code:snapshot-20261018-143005" {
+		t.Fatalf("unexpected default ref: %q", got)
+	}
+	if got := service.SnapshotRef("code", "tuned", now); got != "cradle/code:tuned" {
+		t.Fatalf("unexpected tagged ref: %q", got)
+	}
+}
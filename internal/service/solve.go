@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/moby/client"
+	"github.com/tonistiigi/fsutil"
+	"google.golang.org/grpc"
+)
+
+const (
+	dockerfileFrontend = "dockerfile.v0"
+	// mobyExporter stores the result in the daemon's image store, like the build endpoint.
+	mobyExporter = "moby"
+	// dockerHubRegistry is the host BuildKit asks credentials for on Docker Hub pulls.
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// solveImage builds b through the BuildKit control API the daemon serves on /grpc.
+// opts carries the build args, tags and Dockerfile name BuildOptionsFromSpec derived.
+func solveImage(
+	ctx context.Context,
+	cli *client.Client,
+	contextDir string,
+	files map[string][]byte,
+	b *config.BuildSpec,
+	opts client.ImageBuildOptions,
+	out io.Writer,
+) error {
+	dockerfileDir := contextDir
+	if len(files) > 0 {
+		dir, err := os.MkdirTemp("", "cradle-dockerfile-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		for name, data := range files {
+			if err = os.WriteFile(filepath.Join(dir, name), data, contextFileMode); err != nil {
+				return err
+			}
+		}
+		dockerfileDir = dir
+	}
+	solveOpt, err := SolveOptions(b, opts, contextDir, dockerfileDir)
+	if err != nil {
+		return err
+	}
+
+	c, err := bkclient.New(ctx, "",
+		bkclient.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return cli.DialHijack(ctx, "/grpc", "h2c", nil)
+		}),
+		bkclient.WithSessionDialer(func(ctx context.Context, proto string, meta map[string][]string) (net.Conn, error) {
+			return cli.DialHijack(ctx, "/session", proto, meta)
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("connect to buildkit: %w", err)
+	}
+	defer c.Close()
+
+	statuses := make(chan *bkclient.SolveStatus)
+	rendered := make(chan error, 1)
+	go func() { rendered <- renderSolveStatus(out, statuses) }()
+	_, err = c.Solve(ctx, nil, solveOpt, statuses)
+	renderErr := <-rendered
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
+
+// renderSolveStatus shows BuildKit progress the way build traces are shown. It keeps
+// reading after an error so Solve is never blocked on the channel.
+func renderSolveStatus(out io.Writer, statuses <-chan *bkclient.SolveStatus) (err error) {
+	renderer := newDockerRenderer(out)
+	defer func() {
+		if ferr := renderer.finish(); err == nil && ferr != nil {
+			err = ferr
+		}
+	}()
+	for status := range statuses {
+		if err != nil {
+			continue
+		}
+		for _, sr := range status.Marshal() {
+			if err = renderer.handleStatusResponse(sr); err != nil {
+				break
+			}
+		}
+	}
+	return err
+}
+
+// SolveOptions maps a build onto the dockerfile frontend the way the daemon's build
+// endpoint does, and adds the cache entries of b. Local cache directories must already
+// be resolved. The context is read from contextDir and the Dockerfile from dockerfileDir.
+func SolveOptions(
+	b *config.BuildSpec,
+	opts client.ImageBuildOptions,
+	contextDir, dockerfileDir string,
+) (bkclient.SolveOpt, error) {
+	attrs := map[string]string{"filename": opts.Dockerfile}
+	for key, value := range opts.BuildArgs {
+		if value != nil {
+			attrs["build-arg:"+key] = *value
+		}
+	}
+	for key, value := range opts.Labels {
+		attrs["label:"+key] = value
+	}
+	if opts.Target != "" {
+		attrs["target"] = opts.Target
+	}
+	if opts.NoCache {
+		attrs["no-cache"] = ""
+	}
+	if opts.PullParent {
+		attrs["image-resolve-mode"] = "pull"
+	}
+	if len(b.Platforms) > 0 {
+		attrs["platform"] = strings.Join(b.Platforms, ",")
+	}
+	if len(opts.ExtraHosts) > 0 {
+		attrs["add-hosts"] = strings.Join(opts.ExtraHosts, ",")
+	}
+	if opts.ShmSize > 0 {
+		attrs["shm-size"] = strconv.FormatInt(opts.ShmSize, 10)
+	}
+	if len(opts.Ulimits) > 0 {
+		ulimits := make([]string, 0, len(opts.Ulimits))
+		for _, u := range opts.Ulimits {
+			ulimits = append(ulimits, u.String())
+		}
+		attrs["ulimit"] = strings.Join(ulimits, ",")
+	}
+	if opts.CgroupParent != "" {
+		attrs["cgroup-parent"] = opts.CgroupParent
+	}
+	var allowed []string
+	switch opts.NetworkMode {
+	case "host":
+		attrs["force-network-mode"] = opts.NetworkMode
+		allowed = append(allowed, entitlements.EntitlementNetworkHost.String())
+	case "none":
+		attrs["force-network-mode"] = opts.NetworkMode
+	}
+
+	mounts := map[string]fsutil.FS{}
+	if opts.RemoteContext != "" {
+		attrs["context"] = opts.RemoteContext
+	} else {
+		fs, err := fsutil.NewFS(contextDir)
+		if err != nil {
+			return bkclient.SolveOpt{}, fmt.Errorf("build context: %w", err)
+		}
+		mounts["context"] = fs
+	}
+	if opts.RemoteContext == "" || dockerfileDir != contextDir {
+		fs, err := fsutil.NewFS(dockerfileDir)
+		if err != nil {
+			return bkclient.SolveOpt{}, fmt.Errorf("dockerfile: %w", err)
+		}
+		mounts["dockerfile"] = fs
+	}
+
+	exports := []bkclient.ExportEntry{{
+		Type:  mobyExporter,
+		Attrs: map[string]string{"name": strings.Join(opts.Tags, ",")},
+	}}
+	if len(opts.Outputs) > 0 {
+		exports = exports[:0]
+		for _, output := range opts.Outputs {
+			exports = append(exports, bkclient.ExportEntry{Type: output.Type, Attrs: output.Attrs})
+		}
+	}
+
+	return bkclient.SolveOpt{
+		Frontend:            dockerfileFrontend,
+		FrontendAttrs:       attrs,
+		LocalMounts:         mounts,
+		Exports:             exports,
+		CacheImports:        cacheImports(b),
+		CacheExports:        cacheExports(b, opts),
+		Session:             []session.Attachable{&registryAuth{auths: b.AuthConfigs}},
+		AllowedEntitlements: allowed,
+	}, nil
+}
+
+// cacheImports returns the registry and local cache_from entries of b.
+func cacheImports(b *config.BuildSpec) []bkclient.CacheOptionsEntry {
+	var entries []bkclient.CacheOptionsEntry
+	for _, c := range b.CacheFrom {
+		switch c.Type {
+		case config.CacheTypeRegistry:
+			entries = append(entries, bkclient.CacheOptionsEntry{
+				Type:  string(c.Type),
+				Attrs: map[string]string{"ref": c.Ref},
+			})
+		case config.CacheTypeLocal:
+			entries = append(entries, bkclient.CacheOptionsEntry{
+				Type:  string(c.Type),
+				Attrs: map[string]string{"src": c.Src},
+			})
+		case config.CacheTypeInline:
+		}
+	}
+	return entries
+}
+
+// cacheExports returns the local cache_to entries of b, plus the inline cache when
+// the BUILDKIT_INLINE_CACHE build arg turns it on. Registry entries are pushed after
+// the build, as with the build endpoint.
+func cacheExports(b *config.BuildSpec, opts client.ImageBuildOptions) []bkclient.CacheOptionsEntry {
+	var entries []bkclient.CacheOptionsEntry
+	for _, c := range b.CacheTo {
+		if c.Type == config.CacheTypeLocal {
+			entries = append(entries, bkclient.CacheOptionsEntry{
+				Type:  string(c.Type),
+				Attrs: map[string]string{"dest": c.Dest},
+			})
+		}
+	}
+	if arg := opts.BuildArgs[inlineCacheArg]; arg != nil {
+		if enabled, err := strconv.ParseBool(*arg); err == nil && enabled {
+			entries = append(entries, bkclient.CacheOptionsEntry{Type: string(config.CacheTypeInline)})
+		}
+	}
+	return entries
+}
+
+// registryAuth answers BuildKit credential requests from the auth_configs of a build.
+type registryAuth struct {
+	auth.UnimplementedAuthServer
+
+	auths map[string]config.RegistryAuthSpec
+}
+
+func (a *registryAuth) Register(server *grpc.Server) {
+	auth.RegisterAuthServer(server, a)
+}
+
+func (a *registryAuth) Credentials(
+	_ context.Context,
+	req *auth.CredentialsRequest,
+) (*auth.CredentialsResponse, error) {
+	host := req.GetHost()
+	if host == dockerHubRegistry {
+		host = dockerHubHost
+	}
+	spec, ok := registryAuthForHost(a.auths, host)
+	if !ok {
+		return &auth.CredentialsResponse{}, nil
+	}
+	if spec.IdentityToken != "" {
+		return &auth.CredentialsResponse{Secret: spec.IdentityToken}, nil
+	}
+	username, password := spec.Username, spec.Password
+	if username == "" && spec.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(spec.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("auth_configs %s: invalid auth: %w", host, err)
+		}
+		username, password, _ = strings.Cut(string(decoded), ":")
+	}
+	return &auth.CredentialsResponse{Username: username, Secret: password}, nil
+}
@@ -3,27 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/containerd/errdefs"
 	"github.com/moby/moby/client"
 )
 
-func (s *Service) Stop(ctx context.Context, alias string) (string, error) {
-	a, ok := s.cfg.Aliases[alias]
-	if !ok {
-		return "", fmt.Errorf("unknown alias %q", alias)
-	}
-
-	name := a.Run.Name
-	if name == "" {
-		name = fmt.Sprintf("cradle-%s", alias)
-	}
-
-	ctr, err := s.cli.ContainerInspect(ctx, name, client.ContainerInspectOptions{})
+func (s *Service) Stop(ctx context.Context, target Target, here bool) (string, error) {
+	ctr, _, err := s.inspectTarget(ctx, target, here)
 	if err != nil {
-		if errdefs.IsNotFound(err) {
-			return "", fmt.Errorf("container %q not found", name)
-		}
 		return "", err
 	}
 
@@ -35,3 +22,23 @@ func (s *Service) Stop(ctx context.Context, alias string) (string, error) {
 
 	return ctr.Container.ID, nil
 }
+
+// Remove deletes the target container. Running containers are only removed with force.
+func (s *Service) Remove(ctx context.Context, target Target, here, force bool) (string, error) {
+	ctr, _, err := s.inspectTarget(ctx, target, here)
+	if err != nil {
+		return "", err
+	}
+
+	if !force && ctr.Container.State != nil && ctr.Container.State.Running {
+		return "", fmt.Errorf(
+			"container %q is running; stop it first or use --force",
+			strings.TrimPrefix(ctr.Container.Name, "/"),
+		)
+	}
+	removeOpts := client.ContainerRemoveOptions{Force: force}
+	if _, rmErr := s.cli.ContainerRemove(ctx, ctr.Container.ID, removeOpts); rmErr != nil {
+		return "", rmErr
+	}
+	return ctr.Container.ID, nil
+}
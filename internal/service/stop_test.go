@@ -10,7 +10,7 @@ import (
 
 func TestStopUnknownAlias(t *testing.T) {
 	s := service.NewWithClient(&config.Config{Aliases: map[string]config.Alias{}}, nil)
-	if _, err := s.Stop(context.Background(), "missing"); err == nil {
+	if _, err := s.Stop(context.Background(), service.Target{Alias: "missing"}, false); err == nil {
 		t.Fatalf("expected error for unknown alias")
 	}
 }
@@ -4,30 +4,58 @@ import (
 	"archive/tar"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
+const contextFileMode = 0o644
+
 func TarDir(dir string) io.ReadCloser {
+	return TarContext(dir, nil)
+}
+
+// TarContext streams dir as a tar archive with files added at the root. Entries of
+// dir with the same name as a file are left out. An empty dir yields only files.
+func TarContext(dir string, files map[string][]byte) io.ReadCloser {
 	pr, pw := io.Pipe()
 	go func() {
-		_ = pw.CloseWithError(writeTarDir(dir, pw))
+		_ = pw.CloseWithError(writeTarContext(dir, files, pw))
 	}()
 
 	return pr
 }
 
-func writeTarDir(dir string, pw *io.PipeWriter) error {
+func writeTarContext(dir string, files map[string][]byte, pw *io.PipeWriter) error {
 	tw := tar.NewWriter(pw)
 	defer tw.Close()
 
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+	if dir != "" {
+		walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if rel, err := filepath.Rel(dir, path); err == nil && files[filepath.ToSlash(rel)] != nil {
+				return nil
+			}
+			return writeEntry(dir, path, d, tw)
+		})
 		if walkErr != nil {
 			return walkErr
 		}
-		return writeEntry(dir, path, d, tw)
-	})
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		hdr := &tar.Header{Name: name, Mode: contextFileMode, Size: int64(len(files[name]))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func writeEntry(baseDir, path string, d fs.DirEntry, tw *tar.Writer) error {
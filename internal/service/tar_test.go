@@ -40,3 +40,46 @@ func TestTarDir(t *testing.T) {
 		t.Fatalf("expected file in tar")
 	}
 }
+
+func TestTarContextAddsFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"file.txt": "hello", ".cradle.Dockerfile": "stale"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	rc := service.TarContext(dir, map[string][]byte{".cradle.Dockerfile": []byte("FROM alpine\n")})
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	tr := tar.NewReader(rc)
+	entries := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar read error: %v", err)
+		}
+		if _, dup := entries[hdr.Name]; dup {
+			t.Fatalf("duplicate entry %q", hdr.Name)
+		}
+		data, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(data)
+	}
+	if entries["file.txt"] != "hello" || entries[".cradle.Dockerfile"] != "FROM alpine\n" {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+
+	only := service.TarContext("", map[string][]byte{"Dockerfile": []byte("FROM alpine\n")})
+	defer func() {
+		_ = only.Close()
+	}()
+	hdr, err := tar.NewReader(only).Next()
+	if err != nil || hdr.Name != "Dockerfile" {
+		t.Fatalf("expected in-memory Dockerfile entry, got %v, %v", hdr, err)
+	}
+}
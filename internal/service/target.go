@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rhajizada/cradle/internal/config"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/client"
+)
+
+const (
+	aliasLabel    = "io.cradle.alias"
+	instanceLabel = "io.cradle.instance"
+)
+
+var (
+	instanceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	instanceIndexSuffix = regexp.MustCompile(`[0-9]+$`)
+)
+
+// containerNotFoundError reports a missing alias container; errdefs.IsNotFound matches it.
+type containerNotFoundError struct {
+	name string
+}
+
+func (e containerNotFoundError) Error() string {
+	return fmt.Sprintf("container %q not found", e.name)
+}
+
+func (containerNotFoundError) NotFound() {}
+
+// Target identifies an alias container: the default one, or a named instance
+// written as alias@instance.
+type Target struct {
+	Alias    string
+	Instance string
+}
+
+// ParseTarget parses "alias" or "alias@instance".
+func ParseTarget(raw string) (Target, error) {
+	alias, instance, hasInstance := strings.Cut(raw, "@")
+	if alias == "" {
+		return Target{}, fmt.Errorf("invalid target %q: alias is required", raw)
+	}
+	if hasInstance && !instanceNamePattern.MatchString(instance) {
+		return Target{}, fmt.Errorf("invalid target %q: instance must match %s", raw, instanceNamePattern)
+	}
+	return Target{Alias: alias, Instance: instance}, nil
+}
+
+func (t Target) String() string {
+	if t.Instance == "" {
+		return t.Alias
+	}
+	return t.Alias + "@" + t.Instance
+}
+
+// Index returns the trailing number of the instance name ("web2" is 2), or 0.
+func (t Target) Index() int {
+	n, err := strconv.Atoi(instanceIndexSuffix.FindString(t.Instance))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// ApplyInstance expands the {instance} and {index} placeholders in run.ports and
+// labels the container with its alias and instance. The default instance is unchanged
+// apart from the placeholders.
+func ApplyInstance(run config.RunSpec, t Target) config.RunSpec {
+	replacer := strings.NewReplacer("{instance}", t.Instance, "{index}", strconv.Itoa(t.Index()))
+	if len(run.Ports) > 0 {
+		ports := make([]string, len(run.Ports))
+		for i, spec := range run.Ports {
+			ports[i] = replacer.Replace(spec)
+		}
+		run.Ports = ports
+	}
+	if t.Instance == "" {
+		return run
+	}
+
+	labels := maps.Clone(run.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[aliasLabel] = t.Alias
+	labels[instanceLabel] = t.Instance
+	run.Labels = labels
+	return run
+}
+
+// instanceContainerName appends the instance to the alias container name.
+func instanceContainerName(base string, t Target) string {
+	if t.Instance == "" {
+		return base
+	}
+	return base + "-" + t.Instance
+}
+
+// containerTarget resolves the container name and run spec for t. When mount_cwd
+// is enabled, or here is set, both are keyed to the invoking project directory.
+func (s *Service) containerTarget(t Target, here bool) (string, config.RunSpec, error) {
+	a, ok := s.cfg.Aliases[t.Alias]
+	if !ok {
+		return "", config.RunSpec{}, fmt.Errorf("unknown alias %q", t.Alias)
+	}
+
+	name := instanceContainerName(defaultContainerName(t.Alias, a.Run.Name), t)
+	run := ApplyInstance(a.Run, t)
+	spec := run.MountCwd
+	if here && (spec == nil || !spec.Enabled) {
+		spec = &config.MountCwdSpec{Enabled: true, Target: config.DefaultMountCwdTarget}
+	}
+	if spec == nil || !spec.Enabled {
+		return name, run, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", config.RunSpec{}, err
+	}
+	dir, err := ResolveProjectDir(cwd, BoolDefault(spec.GitRoot, true))
+	if err != nil {
+		return "", config.RunSpec{}, fmt.Errorf("mount_cwd: %w", err)
+	}
+	return ProjectContainerName(name, dir), ApplyMountCwd(run, *spec, dir), nil
+}
+
+// inspectTarget looks up the existing container for t.
+func (s *Service) inspectTarget(
+	ctx context.Context,
+	t Target,
+	here bool,
+) (client.ContainerInspectResult, config.RunSpec, error) {
+	name, run, err := s.containerTarget(t, here)
+	if err != nil {
+		return client.ContainerInspectResult{}, run, err
+	}
+	ctr, err := s.cli.ContainerInspect(ctx, name, client.ContainerInspectOptions{})
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return ctr, run, containerNotFoundError{name: name}
+		}
+		return ctr, run, err
+	}
+	return ctr, run, nil
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/rhajizada/cradle/internal/config"
+	"github.com/rhajizada/cradle/internal/service"
+)
+
+func TestParseTarget(t *testing.T) {
+	cases := map[string]service.Target{
+		"code":       {Alias: "code"},
+		"code@b":     {Alias: "code", Instance: "b"},
+		"code@web-2": {Alias: "code", Instance: "web-2"},
+	}
+	for raw, want := range cases {
+		got, err := service.ParseTarget(raw)
+		if err != nil {
+			t.Fatalf("ParseTarget(%q) error: %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("ParseTarget(%q) = %+v, want %+v", raw, got, want)
+		}
+		if got.String() != raw {
+			t.Fatalf("String() = %q, want %q", got.String(), raw)
+		}
+	}
+
+	for _, raw := range []string{"", "@b", "code@", "code@-b", "code@a/b"} {
+		if _, err := service.ParseTarget(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestTargetIndex(t *testing.T) {
+	cases := map[string]int{"": 0, "b": 0, "2": 2, "web12": 12}
+	for instance, want := range cases {
+		if got := (service.Target{Alias: "code", Instance: instance}).Index(); got != want {
+			t.Fatalf("Index(%q) = %d, want %d", instance, got, want)
+		}
+	}
+}
+
+func TestApplyInstance(t *testing.T) {
+	run := config.RunSpec{
+		Ports:  []string{"844{index}:8443", "127.0.0.1:0:22"},
+		Labels: map[string]string{"team": "dev"},
+	}
+
+	got := service.ApplyInstance(run, service.Target{Alias: "code", Instance: "2"})
+	if got.Ports[0] != "8442:8443" || got.Ports[1] != "127.0.0.1:0:22" {
+		t.Fatalf("unexpected ports: %v", got.Ports)
+	}
+	if got.Labels["io.cradle.alias"] != "code" || got.Labels["io.cradle.instance"] != "2" || got.Labels["team"] != "dev" {
+		t.Fatalf("unexpected labels: %v", got.Labels)
+	}
+	if run.Ports[0] != "844{index}:8443" || len(run.Labels) != 1 {
+		t.Fatalf("expected input run to be left untouched")
+	}
+
+	def := service.ApplyInstance(run, service.Target{Alias: "code"})
+	if def.Ports[0] != "8440:8443" {
+		t.Fatalf("unexpected default ports: %v", def.Ports)
+	}
+	if _, ok := def.Labels["io.cradle.instance"]; ok {
+		t.Fatalf("expected default instance to stay unlabeled")
+	}
+}
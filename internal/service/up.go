@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+)
+
+const healthPollInterval = 500 * time.Millisecond
+
+// StartOrder resolves a group or alias name to the aliases `up` starts, dependencies first.
+func (s *Service) StartOrder(name string) ([]string, error) {
+	members, err := s.cfg.Members(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.cfg.StartOrder(members)
+}
+
+// WaitHealthy blocks until the container reports healthy. Containers without a
+// healthcheck return immediately.
+func (s *Service) WaitHealthy(ctx context.Context, id string) error {
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+	for {
+		ctr, err := s.cli.ContainerInspect(ctx, id, client.ContainerInspectOptions{})
+		if err != nil {
+			return err
+		}
+		state := ctr.Container.State
+		if state == nil || state.Health == nil {
+			return nil
+		}
+		name := strings.TrimPrefix(ctr.Container.Name, "/")
+		switch {
+		case !state.Running:
+			return fmt.Errorf("container %q exited with code %d before becoming healthy", name, state.ExitCode)
+		case state.Health.Status == container.Healthy:
+			return nil
+		case state.Health.Status == container.Unhealthy:
+			return fmt.Errorf("container %q is unhealthy", name)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Down stops the containers of a group or alias and its dependencies in reverse
+// start order, skipping ones that do not exist. It returns the stopped container IDs.
+func (s *Service) Down(ctx context.Context, name string) ([]string, error) {
+	order, err := s.StartOrder(name)
+	if err != nil {
+		return nil, err
+	}
+	var stopped []string
+	for _, alias := range slices.Backward(order) {
+		id, stopErr := s.Stop(ctx, Target{Alias: alias}, false)
+		if errdefs.IsNotFound(stopErr) {
+			continue
+		}
+		if stopErr != nil {
+			return stopped, fmt.Errorf("stop %s: %w", alias, stopErr)
+		}
+		stopped = append(stopped, id)
+	}
+	return stopped, nil
+}